@@ -61,7 +61,10 @@ func InitConfig() error {
 	fileName := "init.go"
 	content := `package config
 
-import "github.com/Arthur-Conti/guh/libs/log/logger"
+import (
+	fl "github.com/Arthur-Conti/guh/libs/fast_logger"
+	"github.com/Arthur-Conti/guh/libs/log/logger"
+)
 
 type BaseConfigs struct {
 	Logger *logger.Logger
@@ -71,6 +74,7 @@ var Config = &BaseConfigs{}
 
 func Init() {
 	Config.Logger = InitLogger()
+	fl.SetDefault(Config.Logger)
 }`
 
 	return createFiles(configFilePath+fileName, content)
@@ -82,26 +86,22 @@ func LoggerConfig() error {
 	content := `package config
 
 import (
-	applicationpackage "github.com/Arthur-Conti/guh/libs/log/application_package"
 	"github.com/Arthur-Conti/guh/libs/log/logger"
-	"github.com/Arthur-Conti/guh/libs/log/outputs"
+	projectconfig "github.com/Arthur-Conti/guh/libs/project_config"
 )
 
+// InitLogger builds the logger from the "logger" section of .guh.yaml; GUH_LOG_LEVEL,
+// GUH_LOG_PACKAGES, GUH_LOG_FORMAT and GUH_LOG_TEMPLATE override it at runtime.
 func InitLogger() *logger.Logger {
-	plainOpts := outputs.PlainOutputOpts{
-		DebugPattern:   "DEBUG: ",
-		WarningPattern: "WARNING: ",
-		InfoPattern:    "INFO: ",
-		ErrorPattern:   "ERROR: ",
+	cfg := logger.Config{}
+	if pc, err := projectconfig.Load(); err == nil {
+		cfg = pc.Logger
 	}
-	plainOutput := outputs.NewPlainOutput(plainOpts)
-	appPackage := applicationpackage.NewPackageLevel()
-	loggerOpts := logger.LoggerOpts{
-		OutputType:         plainOutput,
-		LevelStr:           "debug",
-		ApplicationPackage: *appPackage,
+	l, err := logger.NewFromConfig(logger.ConfigFromEnv(cfg))
+	if err != nil {
+		panic(err)
 	}
-	return logger.NewLogger(loggerOpts)
+	return l
 }`
 
 	return createFiles(configFilePath+fileName, content)
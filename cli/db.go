@@ -610,10 +610,5 @@ func loadAppliedList(p *db.Postgres) ([]appliedRow, error) {
 }
 
 func isUndefinedTableErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	// A simple heuristic for lib/pq error text; avoid importing pq just for code check
-	msg := strings.ToLower(err.Error())
-	return strings.Contains(msg, "undefined table") || strings.Contains(msg, "does not exist")
+	return db.IsUndefinedTable(err)
 }
@@ -74,7 +74,7 @@ func createStructure(serviceName string, modName string) error {
 	if err := createDir(infraDirList, "./internal/infra/"); err != nil {
 		return err
 	}
-	httpDirList := []string{"controllers", "routes"}
+	httpDirList := []string{"controllers", "middlewares", "routes"}
 	if err := createDir(httpDirList, "./internal/infra/http/"); err != nil {
 		return err
 	}
@@ -82,15 +82,109 @@ func createStructure(serviceName string, modName string) error {
 		"./cmd/main.go": fmt.Sprintf(`package main
 
 import (
-	"%v/internal/infra/http/routes"
+	"%[1]v/internal/infra/http/middlewares"
+	"%[1]v/internal/infra/http/routes"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	server := gin.Default()
+	server.Use(middlewares.RequestID(), middlewares.Errors())
 	routes.RouterRegister(server)
 	server.Run(":8080")
 }`, modName),
+		"./internal/infra/http/middlewares/request_id.go": `package middlewares
+
+import (
+	"github.com/Arthur-Conti/guh/libs/log/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID reuses the incoming X-Request-ID header (or generates one), echoes it back
+// and stores a logger carrying it in the request context; read it with logger.FromContext.
+// A W3C traceparent header is continued with a new span so entries carry trace and span IDs.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(logger.RequestIDHeader)
+		if id == "" {
+			id = logger.NewRequestID()
+		}
+		span := logger.NewSpanContext()
+		if parent, err := logger.ParseTraceparent(ctx.GetHeader(logger.TraceparentHeader)); err == nil {
+			span = parent.ChildSpan()
+		}
+		reqCtx := logger.WithRequestID(ctx.Request.Context(), id)
+		reqCtx = logger.WithSpanContext(reqCtx, span)
+		reqCtx = logger.WithContext(reqCtx, logger.FromContext(reqCtx))
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Set(logger.RequestIDField, id)
+		ctx.Header(logger.RequestIDHeader, id)
+		ctx.Header(logger.TraceparentHeader, span.Traceparent())
+		ctx.Next()
+	}
+}`,
+		"./internal/infra/http/middlewares/errors.go": `package middlewares
+
+import (
+	"net/http"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	"github.com/Arthur-Conti/guh/libs/log/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Errors turns errors added with ctx.Error and recovered panics into
+// application/problem+json responses, logging them with the request logger.
+func Errors() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				writeError(ctx, errorhandler.FromPanic(recovered))
+				ctx.Abort()
+			}
+		}()
+		ctx.Next()
+		if len(ctx.Errors) > 0 && !ctx.Writer.Written() {
+			writeError(ctx, ctx.Errors.Last().Err)
+		}
+	}
+}
+
+func writeError(ctx *gin.Context, err error) {
+	logger.FromContext(ctx.Request.Context()).Err(err)
+	errorhandler.WriteProblem(ctx.Writer, ctx.Request, err)
+}`,
+		"./internal/infra/http/middlewares/rate_limit.go": `package middlewares
+
+import (
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	"github.com/Arthur-Conti/guh/libs/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit allows perSecond requests per client IP with bursts of burst, answering the
+// rest with a 429 problem and a Retry-After header.
+// Enable it with server.Use(middlewares.RateLimit(10, 20)).
+func RateLimit(perSecond float64, burst int) gin.HandlerFunc {
+	limiter := ratelimit.NewKeyed(ratelimit.KeyedOpts{
+		New: func() ratelimit.Limiter {
+			return ratelimit.NewTokenBucket(ratelimit.TokenBucketOpts{Rate: perSecond, Per: time.Second, Burst: burst})
+		},
+	})
+	return func(ctx *gin.Context) {
+		if err := limiter.Check(ctx.ClientIP()); err != nil {
+			errorhandler.WriteProblem(ctx.Writer, ctx.Request, err)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}`,
 		"./.env": `DB_USER: 'user_test'
 DB_PASS: 'pass_test'
 DB_IP: 'localhost'
@@ -166,6 +260,10 @@ func showStructure() {
 		"│   └── infra/",
 		"│       ├── http/",
 		"│       │   ├── controllers/",
+		"│       │   ├── middlewares/",
+		"│       │   │   ├── errors.go",
+		"│       │   │   ├── rate_limit.go",
+		"│       │   │   └── request_id.go",
 		"│       │   └── routes/",
 		"│       │   	 └── routes.go",
 		"│       └── repositories/",
@@ -2,6 +2,7 @@ package config
 
 import (
 	envhandler "github.com/Arthur-Conti/guh/libs/env_handler"
+	fl "github.com/Arthur-Conti/guh/libs/fast_logger"
 	"github.com/Arthur-Conti/guh/libs/log/logger"
 )
 
@@ -14,5 +15,6 @@ var Config = &BaseConfigs{}
 
 func Init() {
 	Config.Logger = InitLogger()
+	fl.SetDefault(Config.Logger)
 	Config.Env = InitEnv()
 }
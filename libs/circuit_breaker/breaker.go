@@ -0,0 +1,282 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+// CodeOpen is the errorhandler code of errors returned while the breaker rejects calls.
+const CodeOpen = "CIRCUIT_OPEN"
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
+type BreakerOpts struct {
+	Name string
+	// FailureThreshold opens the breaker after this many failures within Window. When neither
+	// it nor FailureRatio is set it defaults to 5.
+	FailureThreshold int
+	// FailureRatio opens the breaker when failures/requests within Window reaches it,
+	// once MinRequests (default 10) calls were made.
+	FailureRatio float64
+	MinRequests  int
+	// Window is the rolling window, defaults to 10 seconds split in Buckets (default 10).
+	Window  time.Duration
+	Buckets int
+	// CoolDown is how long the breaker stays open before probing, defaults to 30 seconds.
+	CoolDown time.Duration
+	// HalfOpenMaxProbes limits concurrent calls while half-open, defaults to 1.
+	HalfOpenMaxProbes int
+	// HalfOpenSuccesses closes the breaker after that many successful probes, defaults to HalfOpenMaxProbes.
+	HalfOpenSuccesses int
+	// IsFailure defaults to counting errors with a 5xx status (including plain errors),
+	// ignoring client errors and context cancellation.
+	IsFailure     func(error) bool
+	OnStateChange func(name string, from, to State)
+}
+
+type bucket struct {
+	start    int64
+	requests int
+	failures int
+}
+
+type Breaker struct {
+	opts       BreakerOpts
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	openedAt   time.Time
+	buckets    []bucket
+	width      time.Duration
+	probes     int
+	successes  int
+	// now is time.Now, replaced in tests.
+	now func() time.Time
+}
+
+func NewBreaker(opts BreakerOpts) *Breaker {
+	if opts.FailureThreshold <= 0 && opts.FailureRatio <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = 10
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10 * time.Second
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = 10
+	}
+	if opts.CoolDown <= 0 {
+		opts.CoolDown = 30 * time.Second
+	}
+	if opts.HalfOpenMaxProbes <= 0 {
+		opts.HalfOpenMaxProbes = 1
+	}
+	if opts.HalfOpenSuccesses <= 0 {
+		opts.HalfOpenSuccesses = opts.HalfOpenMaxProbes
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = isFailure
+	}
+	return &Breaker{
+		opts:    opts,
+		buckets: make([]bucket, opts.Buckets),
+		// A Window shorter than Buckets nanoseconds would give empty buckets.
+		width: max(opts.Window/time.Duration(opts.Buckets), 1),
+		now:   time.Now,
+	}
+}
+
+func isFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled) && errorhandler.Status(err) >= http.StatusInternalServerError
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	change := b.advance(b.now())
+	state := b.state
+	b.mu.Unlock()
+	b.notify(change)
+	return state
+}
+
+// Allow reserves a call. It returns a KindUnavailable error with a RetryAfter hint when the
+// breaker is open, otherwise done must be called with the call's result.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	now := b.now()
+	change := b.advance(now)
+	switch b.state {
+	case StateOpen:
+		retryAfter := b.openedAt.Add(b.opts.CoolDown).Sub(now)
+		b.mu.Unlock()
+		b.notify(change)
+		return nil, b.rejected("circuit breaker is open", retryAfter)
+	case StateHalfOpen:
+		if b.probes >= b.opts.HalfOpenMaxProbes {
+			b.mu.Unlock()
+			b.notify(change)
+			return nil, b.rejected("circuit breaker is half-open and probing", 0)
+		}
+		b.probes++
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(change)
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(generation, b.opts.IsFailure(err)) })
+	}, nil
+}
+
+// Do runs fn through the breaker.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := Execute(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Execute is Do for functions returning a value, e.g. inside retryhandler.DoValue.
+func Execute[T any](ctx context.Context, b *Breaker, fn func(ctx context.Context) (T, error)) (T, error) {
+	done, err := b.Allow()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	value, err := fn(ctx)
+	done(err)
+	return value, err
+}
+
+// IsOpen reports whether err was returned because a breaker rejected the call.
+func IsOpen(err error) bool {
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if e, ok := current.(*errorhandler.Error); ok && e.Code == CodeOpen {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Breaker) rejected(message string, retryAfter time.Duration) error {
+	return errorhandler.New(errorhandler.KindUnavailable, message,
+		errorhandler.WithOp("circuitbreaker.Breaker.Allow"),
+		errorhandler.WithCode(CodeOpen),
+		errorhandler.WithFields(map[string]any{"breaker": b.opts.Name}),
+		errorhandler.WithRetryAfter(retryAfter))
+}
+
+type stateChange struct {
+	from, to State
+}
+
+func (b *Breaker) notify(change *stateChange) {
+	if change != nil && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(b.opts.Name, change.from, change.to)
+	}
+}
+
+// advance moves an open breaker to half-open once the cool-down is over.
+func (b *Breaker) advance(now time.Time) *stateChange {
+	if b.state == StateOpen && !now.Before(b.openedAt.Add(b.opts.CoolDown)) {
+		return b.setState(StateHalfOpen, now)
+	}
+	return nil
+}
+
+func (b *Breaker) record(generation uint64, failed bool) {
+	b.mu.Lock()
+	now := b.now()
+	change := b.advance(now)
+	if generation != b.generation {
+		b.mu.Unlock()
+		b.notify(change)
+		return
+	}
+	switch b.state {
+	case StateClosed:
+		current := b.bucket(now)
+		current.requests++
+		if failed {
+			current.failures++
+			if b.shouldTrip(now) {
+				change = b.setState(StateOpen, now)
+			}
+		}
+	case StateHalfOpen:
+		b.probes--
+		if failed {
+			change = b.setState(StateOpen, now)
+		} else if b.successes++; b.successes >= b.opts.HalfOpenSuccesses {
+			change = b.setState(StateClosed, now)
+		}
+	}
+	b.mu.Unlock()
+	b.notify(change)
+}
+
+func (b *Breaker) setState(state State, now time.Time) *stateChange {
+	change := &stateChange{from: b.state, to: state}
+	b.state = state
+	b.generation++
+	b.probes = 0
+	b.successes = 0
+	if state == StateOpen {
+		b.openedAt = now
+	}
+	if state == StateClosed {
+		clear(b.buckets)
+	}
+	return change
+}
+
+func (b *Breaker) bucket(now time.Time) *bucket {
+	start := now.UnixNano() / int64(b.width)
+	current := &b.buckets[start%int64(len(b.buckets))]
+	if current.start != start {
+		*current = bucket{start: start}
+	}
+	return current
+}
+
+func (b *Breaker) shouldTrip(now time.Time) bool {
+	oldest := now.UnixNano()/int64(b.width) - int64(len(b.buckets)) + 1
+	var requests, failures int
+	for _, bucket := range b.buckets {
+		if bucket.start >= oldest {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+	if b.opts.FailureThreshold > 0 && failures >= b.opts.FailureThreshold {
+		return true
+	}
+	return b.opts.FailureRatio > 0 && requests >= b.opts.MinRequests && float64(failures)/float64(requests) >= b.opts.FailureRatio
+}
@@ -0,0 +1,205 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(opts BreakerOpts) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	b := NewBreaker(opts)
+	b.now = clock.Now
+	return b, clock
+}
+
+var (
+	errServer = errorhandler.New(errorhandler.KindInternal, "boom")
+	errClient = errorhandler.New(errorhandler.KindInvalidArgument, "bad input")
+)
+
+// call runs one call through b, failing the test when the breaker rejects it.
+func call(t *testing.T, b *Breaker, err error) {
+	t.Helper()
+	done, allowErr := b.Allow()
+	if allowErr != nil {
+		t.Fatalf("Allow() = %v, want a permit", allowErr)
+	}
+	done(err)
+}
+
+func TestBreakerTrips(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		err     error
+	}
+	fail := step{err: errServer}
+	ok := step{}
+	tests := []struct {
+		name  string
+		opts  BreakerOpts
+		steps []step
+		want  State
+	}{
+		{"below threshold", BreakerOpts{FailureThreshold: 3}, []step{fail, fail, ok}, StateClosed},
+		{"threshold reached", BreakerOpts{FailureThreshold: 3}, []step{fail, ok, fail, fail}, StateOpen},
+		{"default threshold", BreakerOpts{}, []step{fail, fail, fail, fail, fail}, StateOpen},
+		{
+			name:  "failures outside the window are forgotten",
+			opts:  BreakerOpts{FailureThreshold: 3, Window: 10 * time.Second},
+			steps: []step{fail, fail, {advance: 11 * time.Second, err: errServer}},
+			want:  StateClosed,
+		},
+		{
+			name:  "failures in older buckets still in the window count",
+			opts:  BreakerOpts{FailureThreshold: 3, Window: 10 * time.Second},
+			steps: []step{fail, {advance: 4 * time.Second, err: errServer}, {advance: 5 * time.Second, err: errServer}},
+			want:  StateOpen,
+		},
+		{"ratio below min requests", BreakerOpts{FailureRatio: 0.5, MinRequests: 4}, []step{fail, fail, fail}, StateClosed},
+		{"ratio reached", BreakerOpts{FailureRatio: 0.5, MinRequests: 4}, []step{ok, ok, fail, fail}, StateOpen},
+		{"ratio not reached", BreakerOpts{FailureRatio: 0.5, MinRequests: 4}, []step{ok, ok, ok, fail, ok, fail}, StateClosed},
+		{"client errors are not failures", BreakerOpts{FailureThreshold: 2}, []step{{err: errClient}, {err: errClient}}, StateClosed},
+		{"cancellation is not a failure", BreakerOpts{FailureThreshold: 2}, []step{{err: context.Canceled}, {err: context.Canceled}}, StateClosed},
+		{"plain errors are failures", BreakerOpts{FailureThreshold: 2}, []step{{err: errors.New("eof")}, {err: errors.New("eof")}}, StateOpen},
+		{"window shorter than buckets", BreakerOpts{FailureThreshold: 2, Window: 5, Buckets: 10}, []step{fail, fail}, StateOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(tt.opts)
+			for _, s := range tt.steps {
+				clock.Advance(s.advance)
+				call(t, b, s.err)
+			}
+			if got := b.State(); got != tt.want {
+				t.Errorf("State() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerStateMachine(t *testing.T) {
+	var changes []string
+	b, clock := newTestBreaker(BreakerOpts{
+		Name:             "users",
+		FailureThreshold: 1,
+		CoolDown:         30 * time.Second,
+		OnStateChange: func(name string, from, to State) {
+			changes = append(changes, name+":"+from.String()+"->"+to.String())
+		},
+	})
+
+	call(t, b, errServer)
+	clock.Advance(10 * time.Second)
+	_, err := b.Allow()
+	if !IsOpen(err) || !errorhandler.IsKind(err, errorhandler.KindUnavailable) {
+		t.Fatalf("Allow() while open = %v, want an open circuit error", err)
+	}
+	if retryAfter, _ := errorhandler.RetryAfter(err); retryAfter != 20*time.Second {
+		t.Errorf("RetryAfter = %v, want 20s", retryAfter)
+	}
+
+	clock.Advance(20 * time.Second)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() after the cool-down = %v, want half-open", got)
+	}
+	call(t, b, errServer)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() after a failed probe = %v, want open", got)
+	}
+
+	clock.Advance(30 * time.Second)
+	call(t, b, nil)
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() after a successful probe = %v, want closed", got)
+	}
+
+	want := []string{
+		"users:closed->open",
+		"users:open->half-open",
+		"users:half-open->open",
+		"users:open->half-open",
+		"users:half-open->closed",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("state changes = %q, want %q", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("state change %d = %q, want %q", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestBreakerHalfOpenProbes(t *testing.T) {
+	b, clock := newTestBreaker(BreakerOpts{FailureThreshold: 1, CoolDown: time.Second, HalfOpenMaxProbes: 2, HalfOpenSuccesses: 3})
+	// A call started while closed must not count once the breaker moved on.
+	stale, _ := b.Allow()
+	call(t, b, errServer)
+	clock.Advance(time.Second)
+
+	first, err := b.Allow()
+	if err != nil {
+		t.Fatalf("first probe rejected: %v", err)
+	}
+	second, err := b.Allow()
+	if err != nil {
+		t.Fatalf("second probe rejected: %v", err)
+	}
+	if _, err := b.Allow(); !IsOpen(err) {
+		t.Fatalf("third concurrent probe = %v, want rejected", err)
+	}
+
+	stale(errServer)
+	first(nil)
+	first(errServer) // done is idempotent
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want half-open", got)
+	}
+	third, err := b.Allow()
+	if err != nil {
+		t.Fatalf("probe after a finished one rejected: %v", err)
+	}
+	second(nil)
+	third(nil)
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() after 3 successful probes = %v, want closed", got)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	b, _ := newTestBreaker(BreakerOpts{FailureThreshold: 1})
+	value, err := Execute(context.Background(), b, func(context.Context) (int, error) { return 42, nil })
+	if value != 42 || err != nil {
+		t.Fatalf("Execute() = %d, %v", value, err)
+	}
+	if err := b.Do(context.Background(), func(context.Context) error { return errServer }); err != errServer {
+		t.Fatalf("Do() = %v, want the call's error", err)
+	}
+	called := false
+	err = b.Do(context.Background(), func(context.Context) error { called = true; return nil })
+	if called || !IsOpen(errorhandler.Wrap(errorhandler.KindUnavailable, "wrapped", err)) {
+		t.Errorf("Do() while open = %v (called %v), want a wrapped open error", err, called)
+	}
+}
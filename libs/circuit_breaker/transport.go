@@ -0,0 +1,42 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"net/http"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+// Transport is an http.RoundTripper that sends requests through a Breaker, counting
+// transport errors and 5xx responses as failures.
+type Transport struct {
+	Breaker *Breaker
+	// Next defaults to http.DefaultTransport.
+	Next http.RoundTripper
+}
+
+func NewTransport(breaker *Breaker, next http.RoundTripper) *Transport {
+	return &Transport{Breaker: breaker, Next: next}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	done, err := t.Breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		done(errorhandler.Wrap(errorhandler.KindUnavailable, "request failed", err, errorhandler.WithOp("circuitbreaker.Transport.RoundTrip")))
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		done(errorhandler.New(errorhandler.KindFromHTTPStatus(resp.StatusCode), fmt.Sprintf("HTTP %d", resp.StatusCode), errorhandler.WithOp("circuitbreaker.Transport.RoundTrip")))
+	} else {
+		done(nil)
+	}
+	return resp, nil
+}
@@ -0,0 +1,66 @@
+package circuitbreaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	var hits atomic.Int32
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	b, _ := newTestBreaker(BreakerOpts{FailureThreshold: 2})
+	client := &http.Client{Transport: NewTransport(b, nil)}
+	get := func() (*http.Response, error) {
+		resp, err := client.Get(server.URL)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	status.Store(http.StatusNotFound)
+	for i := 0; i < 3; i++ {
+		if resp, err := get(); err != nil || resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("4xx request = %v, %v", resp, err)
+		}
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() after 4xx responses = %v, want closed", got)
+	}
+
+	status.Store(http.StatusBadGateway)
+	for i := 0; i < 2; i++ {
+		if resp, err := get(); err != nil || resp.StatusCode != http.StatusBadGateway {
+			t.Fatalf("5xx request = %v, %v, want the response", resp, err)
+		}
+	}
+	before := hits.Load()
+	if _, err := get(); !IsOpen(err) {
+		t.Fatalf("request while open = %v, want an open circuit error", err)
+	}
+	if hits.Load() != before {
+		t.Error("request while open reached the server")
+	}
+}
+
+func TestTransportErrorsAreFailures(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	b, _ := newTestBreaker(BreakerOpts{FailureThreshold: 1})
+	client := &http.Client{Transport: NewTransport(b, http.DefaultTransport)}
+	if _, err := client.Get(server.URL); err == nil || IsOpen(err) {
+		t.Fatalf("request to a closed server = %v, want a transport error", err)
+	}
+	if got := b.State(); got != StateOpen {
+		t.Errorf("State() = %v, want open", got)
+	}
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	"github.com/lib/pq"
+)
+
+// SQLSTATE codes used by TranslateError, see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	NotNullViolation     = "23502"
+	ForeignKeyViolation  = "23503"
+	UniqueViolation      = "23505"
+	CheckViolation       = "23514"
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
+	UndefinedTable       = "42P01"
+	TooManyConnections   = "53300"
+	QueryCanceled        = "57014"
+	AdminShutdown        = "57P01"
+	CrashShutdown        = "57P02"
+	CannotConnectNow     = "57P03"
+)
+
+type translation struct {
+	kind    errorhandler.Kind
+	message string
+}
+
+var sqlStateTranslations = map[string]translation{
+	NotNullViolation:     {errorhandler.KindInvalidArgument, "required value is missing"},
+	ForeignKeyViolation:  {errorhandler.KindFailedPrecondition, "foreign key constraint violated"},
+	UniqueViolation:      {errorhandler.KindAlreadyExists, "record already exists"},
+	CheckViolation:       {errorhandler.KindInvalidArgument, "value violates check constraint"},
+	SerializationFailure: {errorhandler.KindAborted, "transaction conflict"},
+	DeadlockDetected:     {errorhandler.KindAborted, "transaction deadlock"},
+	TooManyConnections:   {errorhandler.KindUnavailable, "database unavailable"},
+	QueryCanceled:        {errorhandler.KindDeadlineExceeded, "query canceled"},
+	AdminShutdown:        {errorhandler.KindUnavailable, "database unavailable"},
+	CrashShutdown:        {errorhandler.KindUnavailable, "database unavailable"},
+	CannotConnectNow:     {errorhandler.KindUnavailable, "database unavailable"},
+}
+
+// SQLState returns the Postgres error code carried by err, "" when it has none.
+func SQLState(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
+func IsUndefinedTable(err error) bool {
+	return SQLState(err) == UndefinedTable
+}
+
+// TranslateError wraps a database/sql or lib/pq error with the matching errorhandler.Kind,
+// falling back to KindInternal with message. Constraint, table and column names are added to Fields.
+func TranslateError(err error, message string, opts ...errorhandler.Option) error {
+	if err == nil {
+		return nil
+	}
+	t := translate(err)
+	if t.message == "" {
+		t.message = message
+	}
+	wrapped := errorhandler.Wrap(t.kind, t.message, err, opts...)
+
+	var pqErr *pq.Error
+	if e, ok := wrapped.(*errorhandler.Error); ok && errors.As(err, &pqErr) {
+		fields := make(map[string]any, len(e.Fields)+4)
+		for key, value := range e.Fields {
+			fields[key] = value
+		}
+		fields["sqlstate"] = string(pqErr.Code)
+		for key, value := range map[string]string{"constraint": pqErr.Constraint, "table": pqErr.Table, "column": pqErr.Column} {
+			if value != "" {
+				fields[key] = value
+			}
+		}
+		e.Fields = fields
+	}
+	return wrapped
+}
+
+func translate(err error) translation {
+	if errors.Is(err, sql.ErrNoRows) {
+		return translation{errorhandler.KindNotFound, "record not found"}
+	}
+	// Context errors come first: lib/pq reports a canceled query as 57014 as well.
+	// Canceled maps to KindAborted, errorhandler.Retryable still reports it as non-retryable.
+	if errors.Is(err, context.Canceled) {
+		return translation{errorhandler.KindAborted, "query canceled"}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return translation{errorhandler.KindDeadlineExceeded, "query timed out"}
+	}
+	if state := SQLState(err); state != "" {
+		if t, ok := sqlStateTranslations[state]; ok {
+			return t
+		}
+		switch {
+		case strings.HasPrefix(state, "08"):
+			return translation{errorhandler.KindUnavailable, "database unavailable"}
+		case strings.HasPrefix(state, "22"):
+			return translation{errorhandler.KindInvalidArgument, "invalid value"}
+		}
+		return translation{kind: errorhandler.KindInternal}
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errorhandler.Retryable(err) {
+		return translation{errorhandler.KindUnavailable, "database unavailable"}
+	}
+	return translation{kind: errorhandler.KindInternal}
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	"github.com/lib/pq"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      errorhandler.Kind
+		message   string
+		retryable bool
+	}{
+		{"not null", &pq.Error{Code: NotNullViolation}, errorhandler.KindInvalidArgument, "required value is missing", false},
+		{"foreign key", &pq.Error{Code: ForeignKeyViolation}, errorhandler.KindFailedPrecondition, "foreign key constraint violated", false},
+		{"unique", &pq.Error{Code: UniqueViolation}, errorhandler.KindAlreadyExists, "record already exists", false},
+		{"check", &pq.Error{Code: CheckViolation}, errorhandler.KindInvalidArgument, "value violates check constraint", false},
+		{"serialization", &pq.Error{Code: SerializationFailure}, errorhandler.KindAborted, "transaction conflict", true},
+		{"deadlock", &pq.Error{Code: DeadlockDetected}, errorhandler.KindAborted, "transaction deadlock", true},
+		{"too many connections", &pq.Error{Code: TooManyConnections}, errorhandler.KindUnavailable, "database unavailable", true},
+		{"query canceled", &pq.Error{Code: QueryCanceled}, errorhandler.KindDeadlineExceeded, "query canceled", true},
+		{"admin shutdown", &pq.Error{Code: AdminShutdown}, errorhandler.KindUnavailable, "database unavailable", true},
+		{"crash shutdown", &pq.Error{Code: CrashShutdown}, errorhandler.KindUnavailable, "database unavailable", true},
+		{"cannot connect now", &pq.Error{Code: CannotConnectNow}, errorhandler.KindUnavailable, "database unavailable", true},
+		{"connection exception class", &pq.Error{Code: "08006"}, errorhandler.KindUnavailable, "database unavailable", true},
+		{"data exception class", &pq.Error{Code: "22P02"}, errorhandler.KindInvalidArgument, "invalid value", false},
+		{"unmapped state", &pq.Error{Code: UndefinedTable}, errorhandler.KindInternal, "Query failed", false},
+		{"no rows", sql.ErrNoRows, errorhandler.KindNotFound, "record not found", false},
+		{"wrapped no rows", fmt.Errorf("scan: %w", sql.ErrNoRows), errorhandler.KindNotFound, "record not found", false},
+		{"wrapped pq error", fmt.Errorf("insert: %w", &pq.Error{Code: UniqueViolation}), errorhandler.KindAlreadyExists, "record already exists", false},
+		{"canceled", context.Canceled, errorhandler.KindAborted, "query canceled", false},
+		{"wrapped canceled", fmt.Errorf("query: %w", context.Canceled), errorhandler.KindAborted, "query canceled", false},
+		{"deadline", context.DeadlineExceeded, errorhandler.KindDeadlineExceeded, "query timed out", true},
+		{"bad conn", driver.ErrBadConn, errorhandler.KindUnavailable, "database unavailable", true},
+		{"conn done", sql.ErrConnDone, errorhandler.KindUnavailable, "database unavailable", true},
+		{"other", errors.New("boom"), errorhandler.KindInternal, "Query failed", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := TranslateError(tt.err, "Query failed", errorhandler.WithOp("db.Query"))
+			var e *errorhandler.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("TranslateError() = %T, want *errorhandler.Error", err)
+			}
+			if e.Kind != tt.kind || e.Message != tt.message {
+				t.Errorf("got kind %v message %q, want %v %q", e.Kind, e.Message, tt.kind, tt.message)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("translated error does not wrap %v", tt.err)
+			}
+			if got := errorhandler.Retryable(err); got != tt.retryable {
+				t.Errorf("Retryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestTranslateErrorFields(t *testing.T) {
+	if TranslateError(nil, "Query failed") != nil {
+		t.Error("TranslateError(nil) != nil")
+	}
+	pqErr := &pq.Error{Code: UniqueViolation, Table: "users", Constraint: "users_email_key"}
+	err := TranslateError(pqErr, "Query failed", errorhandler.WithFields(map[string]any{"query": "INSERT"}))
+	e := err.(*errorhandler.Error)
+	want := map[string]any{"query": "INSERT", "sqlstate": UniqueViolation, "table": "users", "constraint": "users_email_key"}
+	if len(e.Fields) != len(want) {
+		t.Errorf("Fields = %v, want %v", e.Fields, want)
+	}
+	for key, value := range want {
+		if e.Fields[key] != value {
+			t.Errorf("Fields[%q] = %v, want %v", key, e.Fields[key], value)
+		}
+	}
+	if SQLState(err) != UniqueViolation || !IsUndefinedTable(&pq.Error{Code: UndefinedTable}) {
+		t.Error("SQLState does not see the wrapped pq error")
+	}
+}
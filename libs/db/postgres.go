@@ -89,7 +89,7 @@ func (p *Postgres) init() error {
 func (p *Postgres) CreateTable(sql string) error {
 	_, err := p.Conn.Query(sql)
 	if err != nil {
-		return errorhandler.Wrap(errorhandler.KindInternal, "Error creating table", err, errorhandler.WithOp("db.CreateTable"), errorhandler.WithFields(map[string]any{"sql": sql}))
+		return TranslateError(err, "Error creating table", errorhandler.WithOp("db.CreateTable"), errorhandler.WithFields(map[string]any{"sql": sql}))
 	}
 	return nil
 }
@@ -102,11 +102,14 @@ func (p *Postgres) QueryRow(dest any, query string, args ...any) error {
 
 	rows, err := p.Conn.Query(query, args...)
 	if err != nil {
-		return errorhandler.Wrap(errorhandler.KindInternal, "Query failed", err, errorhandler.WithOp("db.QueryRow"), errorhandler.WithFields(map[string]any{"query": query, "args": args}))
+		return TranslateError(err, "Query failed", errorhandler.WithOp("db.QueryRow"), errorhandler.WithFields(map[string]any{"query": query, "args": args}))
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return TranslateError(err, "Query failed", errorhandler.WithOp("db.QueryRow"), errorhandler.WithFields(map[string]any{"query": query, "args": args}))
+		}
 		return errorhandler.New(errorhandler.KindNotFound, "error no rows", errorhandler.WithOp("db.QueryRow"))
 	}
 
@@ -169,7 +172,7 @@ func (p *Postgres) Query(dest any, query string, args ...any) error {
 
 	rows, err := p.Conn.Query(query, args...)
 	if err != nil {
-		return errorhandler.Wrap(errorhandler.KindInternal, "Query failed", err, errorhandler.WithOp("db.Query"), errorhandler.WithFields(map[string]any{"query": query, "args": args}))
+		return TranslateError(err, "Query failed", errorhandler.WithOp("db.Query"), errorhandler.WithFields(map[string]any{"query": query, "args": args}))
 	}
 	defer rows.Close()
 
@@ -216,6 +219,9 @@ func (p *Postgres) Query(dest any, query string, args ...any) error {
 
 		sliceVal.Set(reflect.Append(sliceVal, newElem))
 	}
+	if err := rows.Err(); err != nil {
+		return TranslateError(err, "Query failed", errorhandler.WithOp("db.Query"), errorhandler.WithFields(map[string]any{"query": query, "args": args}))
+	}
 
 	return nil
 }
@@ -0,0 +1,187 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CodeInfo describes a stable, machine readable error code such as "ORDER_NOT_FOUND".
+type CodeInfo struct {
+	Code    string
+	Kind    Kind
+	Message string
+	// HttpStatus overrides Kind.HttpStatus() in problem responses when set.
+	HttpStatus int
+}
+
+var (
+	codesMu sync.RWMutex
+	codes   = map[string]CodeInfo{}
+	catalog *Catalog
+)
+
+func WithCode(code string) Option {
+	return func(e *Error) {
+		e.Code = code
+	}
+}
+
+func RegisterCode(info CodeInfo) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	codes[info.Code] = info
+}
+
+func LookupCode(code string) (CodeInfo, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	info, ok := codes[code]
+	return info, ok
+}
+
+// NewCode creates an error with the Kind and default message registered for code.
+func NewCode(code string, opts ...Option) error {
+	info, ok := LookupCode(code)
+	if !ok {
+		info = CodeInfo{Code: code, Kind: KindUnknown, Message: code}
+	}
+	return New(info.Kind, info.Message, append([]Option{WithCode(code)}, opts...)...)
+}
+
+// SetCatalog sets the catalog used to localise problem details; nil disables localisation.
+func SetCatalog(c *Catalog) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	catalog = c
+}
+
+func currentCatalog() *Catalog {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	return catalog
+}
+
+func (e *Error) httpStatus() int {
+	if e.Code != "" {
+		if info, ok := LookupCode(e.Code); ok && info.HttpStatus != 0 {
+			return info.HttpStatus
+		}
+	}
+	return e.Kind.HttpStatus()
+}
+
+// Catalog holds localised messages keyed by error code and locale.
+type Catalog struct {
+	// Fallback is used when none of the requested locales has a message, e.g. "en".
+	Fallback string
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{Fallback: fallback, messages: map[string]map[string]string{}}
+}
+
+// LoadCatalog reads a YAML or JSON file shaped as {code: {locale: message}}.
+func LoadCatalog(path, fallback string) (*Catalog, error) {
+	c := NewCatalog(fallback)
+	if err := c.LoadFile(path); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Catalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Wrap(KindInternal, "Error reading error catalog", err, WithOp("errorhandler.Catalog.LoadFile"), WithFields(map[string]any{"path": path}))
+	}
+	var messages map[string]map[string]string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &messages)
+	} else {
+		err = yaml.Unmarshal(data, &messages)
+	}
+	if err != nil {
+		return Wrap(KindInvalidArgument, "Error parsing error catalog", err, WithOp("errorhandler.Catalog.LoadFile"), WithFields(map[string]any{"path": path}))
+	}
+	for code, locales := range messages {
+		for locale, message := range locales {
+			c.Add(code, locale, message)
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) Add(code, locale, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[code] == nil {
+		c.messages[code] = map[string]string{}
+	}
+	c.messages[code][strings.ToLower(locale)] = message
+}
+
+// Message returns the message for code in the first matching locale. "pt-BR" falls back
+// to "pt" before trying the next locale, and the Fallback locale is tried last.
+func (c *Catalog) Message(code string, locales ...string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	messages := c.messages[code]
+	if len(messages) == 0 {
+		return "", false
+	}
+	candidates := make([]string, 0, len(locales)+1)
+	candidates = append(append(candidates, locales...), c.Fallback)
+	for _, locale := range candidates {
+		locale = strings.ToLower(locale)
+		for locale != "" {
+			if message, ok := messages[locale]; ok {
+				return message, true
+			}
+			cut := strings.LastIndexByte(locale, '-')
+			if cut < 0 {
+				break
+			}
+			locale = locale[:cut]
+		}
+	}
+	return "", false
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header ordered by quality.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			entries = append(entries, weighted{locale: locale, q: q})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	locales := make([]string, len(entries))
+	for i, entry := range entries {
+		locales[i] = entry.locale
+	}
+	return locales
+}
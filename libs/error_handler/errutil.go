@@ -1,13 +1,42 @@
 package errorhandler
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"runtime"
+	"sync/atomic"
+	"time"
+
+	redacthandler "github.com/Arthur-Conti/guh/libs/redact_handler"
 )
 
+var redactor atomic.Pointer[redacthandler.Redactor]
+
+func init() {
+	redactor.Store(redacthandler.Default())
+}
+
+// SetRedactor changes how secrets are masked when errors are serialized; nil disables redaction.
+func SetRedactor(r *redacthandler.Redactor) {
+	redactor.Store(r)
+}
+
+func redactFields(fields map[string]any) map[string]any {
+	r := redactor.Load()
+	if r == nil {
+		return fields
+	}
+	return r.Fields(fields)
+}
+
+func redactString(s string) string {
+	r := redactor.Load()
+	if r == nil {
+		return s
+	}
+	return r.String(s)
+}
+
 type Kind int
 
 const (
@@ -27,6 +56,30 @@ const (
 	KindDeadlineExceeded
 )
 
+var kindNames = map[Kind]string{
+	KindUnknown:            "unknown",
+	KindInvalidArgument:    "invalid_argument",
+	KindUnauthenticated:    "unauthenticated",
+	KindPermissionDenied:   "permission_denied",
+	KindNotFound:           "not_found",
+	KindAlreadyExists:      "already_exists",
+	KindResourceExhausted:  "resource_exhausted",
+	KindFailedPrecondition: "failed_precondition",
+	KindAborted:            "aborted",
+	KindOutOfRange:         "out_of_range",
+	KindUnimplemented:      "unimplemented",
+	KindInternal:           "internal",
+	KindUnavailable:        "unavailable",
+	KindDeadlineExceeded:   "deadline_exceeded",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
 func (k Kind) HttpStatus() int {
 	switch k {
 	case KindInvalidArgument:
@@ -64,9 +117,19 @@ type Error struct {
 	Kind    Kind
 	Op      string
 	Message string
-	Fields  map[string]any
-	Cause   error
-	stack   []uintptr
+	// Code is an optional stable identifier such as "ORDER_NOT_FOUND", see RegisterCode.
+	Code string
+	// Fields are kept for logs and JSON encoding, they never reach problem responses.
+	Fields map[string]any
+	// PublicFields are sent to clients as problem extras, see WithPublicFields.
+	PublicFields map[string]any
+	Cause        error
+	// Violations lists the invalid request fields of a validation error.
+	Violations []FieldViolation
+	// RetryAfter is a hint for how long callers should wait before retrying, 0 when unknown.
+	RetryAfter time.Duration
+	retryable  *bool
+	stack      []uintptr
 }
 
 func (e *Error) Error() string {
@@ -80,6 +143,11 @@ func (e *Error) Unwrap() error {
 	return e.Cause
 }
 
+// Callers returns the program counters captured by WithStack or CaptureStackFor, nil when no stack was captured.
+func (e *Error) Callers() []uintptr {
+	return e.stack
+}
+
 type Option func(*Error)
 
 func WithOp(op string) Option {
@@ -94,6 +162,14 @@ func WithFields(f map[string]any) Option {
 	}
 }
 
+// WithPublicFields sets the fields exposed in the extras of problem responses.
+// Use WithFields for internal details such as queries or arguments.
+func WithPublicFields(f map[string]any) Option {
+	return func(e *Error) {
+		e.PublicFields = f
+	}
+}
+
 func WithCause(cause error) Option {
 	return func(e *Error) {
 		e.Cause = cause
@@ -102,8 +178,7 @@ func WithCause(cause error) Option {
 
 func WithStack() Option {
 	return func(e *Error) {
-		e.stack = make([]uintptr, 10)
-		e.stack = e.stack[:runtime.Callers(3, e.stack)]
+		e.stack = callers(2)
 	}
 }
 
@@ -112,6 +187,9 @@ func New(kind Kind, message string, opts ...Option) error {
 	for _, opt := range opts {
 		opt(e)
 	}
+	if e.stack == nil && captureStack(e.Kind) {
+		e.stack = callers(1)
+	}
 	return e
 }
 
@@ -120,6 +198,9 @@ func Wrap(kind Kind, message string, err error, opts ...Option) error {
 	for _, opt := range opts {
 		opt(e)
 	}
+	if e.stack == nil && captureStack(e.Kind) {
+		e.stack = callers(1)
+	}
 	return e
 }
 
@@ -132,9 +213,15 @@ func IsKind(err error, kind Kind) bool {
 }
 
 func Status(err error) int {
+	if multi, ok := asMulti(err); ok {
+		if len(multi.Errors) == 1 {
+			return Status(multi.Errors[0])
+		}
+		return multi.Kind().HttpStatus()
+	}
 	var e *Error
 	if errors.As(err, &e) {
-		return e.Kind.HttpStatus()
+		return e.httpStatus()
 	}
 	return http.StatusInternalServerError
 }
@@ -144,41 +231,43 @@ type Problem struct {
 	Title    string         `json:"title"`
 	Status   int            `json:"status"`
 	Detail   string         `json:"detail"`
-	Instance string         `json:"instance"`
-	Extras   map[string]any `json:"extras"`
+	Instance string         `json:"instance,omitempty"`
+	Kind     string         `json:"kind,omitempty"`
+	Code     string         `json:"code,omitempty"`
+	Extras   map[string]any `json:"extras,omitempty"`
 }
 
-func toProblem(err error) (int, []byte) {
+// toProblem localises coded errors with the catalog in the first matching locale.
+func toProblem(err error, instance string, locales []string) Problem {
+	if multi, ok := asMulti(err); ok {
+		return multi.toProblem(instance, locales)
+	}
 	var e *Error
 	if !errors.As(err, &e) {
 		s := http.StatusInternalServerError
-		p := Problem{
-			Type:     fmt.Sprintf("about:blank#%d", s),
+		return Problem{
+			Type:     problemType(KindUnknown, s),
 			Title:    http.StatusText(s),
 			Status:   s,
-			Detail:   err.Error(),
-			Instance: e.Op,
+			Detail:   redactString(err.Error()),
+			Instance: instance,
 		}
-		b, _ := json.Marshal(p)
-		return s, b
 	}
-	s := e.Kind.HttpStatus()
-	p := Problem{
-		Type:   fmt.Sprintf("about:blank#%d", s),
-		Title:  http.StatusText(s),
-		Status: s,
-		Detail: e.Message,
-		Extras: e.Fields,
+	s := e.httpStatus()
+	detail := e.Message
+	if c := currentCatalog(); c != nil && e.Code != "" {
+		if message, ok := c.Message(e.Code, locales...); ok {
+			detail = message
+		}
 	}
-	b, _ := json.Marshal(p)
-	return s, b
-}
-
-func Retryable(err error) bool {
-	switch {
-	case IsKind(err, KindUnavailable), IsKind(err, KindAborted), IsKind(err, KindResourceExhausted):
-		return false
-	default:
-		return false
+	return Problem{
+		Type:     problemType(e.Kind, s),
+		Title:    http.StatusText(s),
+		Status:   s,
+		Detail:   redactString(detail),
+		Instance: instance,
+		Kind:     e.Kind.String(),
+		Code:     e.Code,
+		Extras:   withViolations(redactFields(e.PublicFields), e.Violations),
 	}
 }
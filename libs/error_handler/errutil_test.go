@@ -0,0 +1,35 @@
+package errorhandler
+
+import (
+	"sync"
+	"testing"
+
+	redacthandler "github.com/Arthur-Conti/guh/libs/redact_handler"
+)
+
+func TestSetRedactor(t *testing.T) {
+	t.Cleanup(func() { SetRedactor(redacthandler.Default()) })
+	err := New(KindInternal, "dial postgres://bob:hunter2@db/app failed")
+
+	if got := NewProblem(err, "").Detail; got != "dial postgres://bob:"+redacthandler.DefaultMask+"@db/app failed" {
+		t.Errorf("default redaction detail = %q", got)
+	}
+	SetRedactor(nil)
+	if got := NewProblem(err, "").Detail; got != "dial postgres://bob:hunter2@db/app failed" {
+		t.Errorf("detail without redactor = %q", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetRedactor(redacthandler.Default())
+		}()
+		go func() {
+			defer wg.Done()
+			NewProblem(err, "")
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,205 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GRPCCode mirrors google.golang.org/grpc/codes.Code, convert with codes.Code(code).
+type GRPCCode uint32
+
+const (
+	GRPCOK GRPCCode = iota
+	GRPCCanceled
+	GRPCUnknown
+	GRPCInvalidArgument
+	GRPCDeadlineExceeded
+	GRPCNotFound
+	GRPCAlreadyExists
+	GRPCPermissionDenied
+	GRPCResourceExhausted
+	GRPCFailedPrecondition
+	GRPCAborted
+	GRPCOutOfRange
+	GRPCUnimplemented
+	GRPCInternal
+	GRPCUnavailable
+	GRPCDataLoss
+	GRPCUnauthenticated
+)
+
+var kindGRPCCodes = map[Kind]GRPCCode{
+	KindUnknown:            GRPCUnknown,
+	KindInvalidArgument:    GRPCInvalidArgument,
+	KindUnauthenticated:    GRPCUnauthenticated,
+	KindPermissionDenied:   GRPCPermissionDenied,
+	KindNotFound:           GRPCNotFound,
+	KindAlreadyExists:      GRPCAlreadyExists,
+	KindResourceExhausted:  GRPCResourceExhausted,
+	KindFailedPrecondition: GRPCFailedPrecondition,
+	KindAborted:            GRPCAborted,
+	KindOutOfRange:         GRPCOutOfRange,
+	KindUnimplemented:      GRPCUnimplemented,
+	KindInternal:           GRPCInternal,
+	KindUnavailable:        GRPCUnavailable,
+	KindDeadlineExceeded:   GRPCDeadlineExceeded,
+}
+
+func (k Kind) GRPCCode() GRPCCode {
+	if code, ok := kindGRPCCodes[k]; ok {
+		return code
+	}
+	return GRPCUnknown
+}
+
+// KindFromGRPC is the inverse of Kind.GRPCCode; Canceled maps to KindUnknown and DataLoss to KindInternal.
+func KindFromGRPC(code GRPCCode) Kind {
+	if code == GRPCDataLoss {
+		return KindInternal
+	}
+	for kind, kindCode := range kindGRPCCodes {
+		if kindCode == code {
+			return kind
+		}
+	}
+	return KindUnknown
+}
+
+// KindFromHTTPStatus guesses the Kind of an HTTP error status, 409 maps to KindAlreadyExists.
+func KindFromHTTPStatus(status int) Kind {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return KindInvalidArgument
+	case http.StatusUnauthorized:
+		return KindUnauthenticated
+	case http.StatusForbidden:
+		return KindPermissionDenied
+	case http.StatusNotFound:
+		return KindNotFound
+	case http.StatusConflict:
+		return KindAlreadyExists
+	case http.StatusTooManyRequests:
+		return KindResourceExhausted
+	case http.StatusPreconditionFailed:
+		return KindFailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return KindOutOfRange
+	case http.StatusNotImplemented:
+		return KindUnimplemented
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return KindUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return KindDeadlineExceeded
+	}
+	switch {
+	case status >= 500:
+		return KindInternal
+	case status >= 400:
+		return KindInvalidArgument
+	}
+	return KindUnknown
+}
+
+const ErrorInfoType = "type.googleapis.com/google.rpc.ErrorInfo"
+
+// fieldMetadataPrefix keeps field names apart from "op" in the ErrorInfo metadata.
+const fieldMetadataPrefix = "field."
+
+// GRPCStatus mirrors google.rpc.Status in its JSON form, so it can be sent as gRPC status
+// details or over any transport without depending on grpc.
+type GRPCStatus struct {
+	Code    GRPCCode       `json:"code"`
+	Message string         `json:"message"`
+	Details []StatusDetail `json:"details,omitempty"`
+}
+
+// StatusDetail mirrors google.rpc.ErrorInfo. Reason is the upper-case Kind name and Metadata
+// holds the Op under "op" plus the Fields as JSON encoded values under "field.<name>".
+type StatusDetail struct {
+	Type     string            `json:"@type"`
+	Reason   string            `json:"reason,omitempty"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ToGRPCStatus converts err into a GRPCStatus, errors that are not an *Error become GRPCUnknown.
+func ToGRPCStatus(err error) GRPCStatus {
+	if err == nil {
+		return GRPCStatus{Code: GRPCOK}
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		return GRPCStatus{Code: GRPCUnknown, Message: redactString(err.Error())}
+	}
+	metadata := map[string]string{}
+	if e.Op != "" {
+		metadata["op"] = e.Op
+	}
+	for key, value := range redactFields(e.Fields) {
+		b, marshalErr := json.Marshal(value)
+		if marshalErr != nil {
+			b, _ = json.Marshal(fmt.Sprint(value))
+		}
+		metadata[fieldMetadataPrefix+key] = string(b)
+	}
+	detail := StatusDetail{Type: ErrorInfoType, Reason: strings.ToUpper(e.Kind.String())}
+	if len(metadata) > 0 {
+		detail.Metadata = metadata
+	}
+	return GRPCStatus{Code: e.Kind.GRPCCode(), Message: redactString(e.Message), Details: []StatusDetail{detail}}
+}
+
+// FromGRPCStatus rebuilds an *Error from s, nil when s is OK. "field.<name>" metadata values
+// that are not valid JSON, and metadata set by other services without the prefix, are kept
+// as strings.
+func FromGRPCStatus(s GRPCStatus) error {
+	if s.Code == GRPCOK {
+		return nil
+	}
+	e := &Error{Kind: KindFromGRPC(s.Code), Message: s.Message}
+	for _, detail := range s.Details {
+		if detail.Type != ErrorInfoType {
+			continue
+		}
+		if kind, ok := kindFromName(strings.ToLower(detail.Reason)); ok {
+			e.Kind = kind
+		}
+		for key, value := range detail.Metadata {
+			if key == "op" {
+				e.Op = value
+				continue
+			}
+			if e.Fields == nil {
+				e.Fields = map[string]any{}
+			}
+			name, prefixed := strings.CutPrefix(key, fieldMetadataPrefix)
+			if !prefixed {
+				// Prefixed fields win over foreign metadata of the same name.
+				if _, ok := e.Fields[name]; !ok {
+					e.Fields[name] = value
+				}
+				continue
+			}
+			var decoded any
+			if json.Unmarshal([]byte(value), &decoded) == nil {
+				e.Fields[name] = decoded
+			} else {
+				e.Fields[name] = value
+			}
+		}
+		break
+	}
+	return e
+}
+
+func kindFromName(name string) (Kind, bool) {
+	for kind, kindName := range kindNames {
+		if kindName == name {
+			return kind, true
+		}
+	}
+	return KindUnknown, false
+}
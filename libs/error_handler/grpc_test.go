@@ -0,0 +1,116 @@
+package errorhandler
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestKindGRPCCode(t *testing.T) {
+	for kind, code := range kindGRPCCodes {
+		if got := kind.GRPCCode(); got != code {
+			t.Errorf("%v.GRPCCode() = %d, want %d", kind, got, code)
+		}
+		if got := KindFromGRPC(code); got != kind {
+			t.Errorf("KindFromGRPC(%d) = %v, want %v", code, got, kind)
+		}
+	}
+	tests := []struct {
+		code GRPCCode
+		want Kind
+	}{
+		{GRPCOK, KindUnknown},
+		{GRPCCanceled, KindUnknown},
+		{GRPCDataLoss, KindInternal},
+		{GRPCCode(99), KindUnknown},
+	}
+	for _, tt := range tests {
+		if got := KindFromGRPC(tt.code); got != tt.want {
+			t.Errorf("KindFromGRPC(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+	if got := Kind(99).GRPCCode(); got != GRPCUnknown {
+		t.Errorf("unknown kind GRPCCode() = %d, want %d", got, GRPCUnknown)
+	}
+}
+
+func TestKindFromHTTPStatus(t *testing.T) {
+	// Every kind but KindAborted, which shares 409 with KindAlreadyExists, survives a round trip.
+	for kind := range kindNames {
+		if kind == KindUnknown || kind == KindAborted {
+			continue
+		}
+		if got := KindFromHTTPStatus(kind.HttpStatus()); got != kind {
+			t.Errorf("KindFromHTTPStatus(%d) = %v, want %v", kind.HttpStatus(), got, kind)
+		}
+	}
+	tests := []struct {
+		status int
+		want   Kind
+	}{
+		{http.StatusUnprocessableEntity, KindInvalidArgument},
+		{http.StatusBadGateway, KindUnavailable},
+		{http.StatusRequestTimeout, KindDeadlineExceeded},
+		{http.StatusTeapot, KindInvalidArgument},
+		{http.StatusInsufficientStorage, KindInternal},
+		{http.StatusOK, KindUnknown},
+		{http.StatusFound, KindUnknown},
+	}
+	for _, tt := range tests {
+		if got := KindFromHTTPStatus(tt.status); got != tt.want {
+			t.Errorf("KindFromHTTPStatus(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	err := New(KindNotFound, "user not found", WithOp("users.Get"), WithFields(map[string]any{
+		"op":   "shadowed",
+		"id":   7,
+		"tags": []string{"a", "b"},
+	}))
+
+	s := ToGRPCStatus(err)
+	if s.Code != GRPCNotFound || s.Message != "user not found" || len(s.Details) != 1 {
+		t.Fatalf("ToGRPCStatus() = %+v", s)
+	}
+	wantMetadata := map[string]string{"op": "users.Get", "field.op": `"shadowed"`, "field.id": "7", "field.tags": `["a","b"]`}
+	if detail := s.Details[0]; detail.Type != ErrorInfoType || detail.Reason != "NOT_FOUND" || !reflect.DeepEqual(detail.Metadata, wantMetadata) {
+		t.Errorf("detail = %+v, want reason NOT_FOUND and metadata %v", detail, wantMetadata)
+	}
+
+	var got *Error
+	if !errors.As(FromGRPCStatus(s), &got) {
+		t.Fatalf("FromGRPCStatus() is not an *Error")
+	}
+	wantFields := map[string]any{"op": "shadowed", "id": float64(7), "tags": []any{"a", "b"}}
+	if got.Kind != KindNotFound || got.Op != "users.Get" || got.Message != "user not found" || !reflect.DeepEqual(got.Fields, wantFields) {
+		t.Errorf("FromGRPCStatus() = %+v, want op users.Get and fields %v", got, wantFields)
+	}
+}
+
+func TestFromGRPCStatus(t *testing.T) {
+	if err := FromGRPCStatus(ToGRPCStatus(nil)); err != nil {
+		t.Errorf("FromGRPCStatus(OK) = %v, want nil", err)
+	}
+
+	s := ToGRPCStatus(errors.New("boom"))
+	var e *Error
+	if s.Code != GRPCUnknown || !errors.As(FromGRPCStatus(s), &e) || e.Kind != KindUnknown || e.Message != "boom" {
+		t.Errorf("plain error round trip = %+v, %+v", s, e)
+	}
+
+	foreign := GRPCStatus{Code: GRPCInternal, Message: "quota", Details: []StatusDetail{{
+		Type:     ErrorInfoType,
+		Reason:   "RESOURCE_EXHAUSTED",
+		Metadata: map[string]string{"service": "billing", "field.service": `"orders"`, "field.raw": "not json"},
+	}}}
+	if !errors.As(FromGRPCStatus(foreign), &e) {
+		t.Fatal("FromGRPCStatus() is not an *Error")
+	}
+	wantFields := map[string]any{"service": "orders", "raw": "not json"}
+	if e.Kind != KindResourceExhausted || !reflect.DeepEqual(e.Fields, wantFields) {
+		t.Errorf("FromGRPCStatus() = %+v, want kind resource_exhausted and fields %v", e, wantFields)
+	}
+}
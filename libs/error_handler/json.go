@@ -0,0 +1,128 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText accepts kind names ("not_found") and, for older payloads, numbers.
+func (k *Kind) UnmarshalText(text []byte) error {
+	if kind, ok := kindFromName(string(text)); ok {
+		*k = kind
+		return nil
+	}
+	n, err := strconv.Atoi(string(text))
+	if err != nil {
+		return New(KindInvalidArgument, "unknown error kind "+strconv.Quote(string(text)), WithOp("errorhandler.Kind.UnmarshalText"))
+	}
+	*k = Kind(n)
+	return nil
+}
+
+type errorJSON struct {
+	Kind         Kind             `json:"kind"`
+	Op           string           `json:"op,omitempty"`
+	Message      string           `json:"message"`
+	Code         string           `json:"code,omitempty"`
+	Fields       map[string]any   `json:"fields,omitempty"`
+	PublicFields map[string]any   `json:"public_fields,omitempty"`
+	Violations   []FieldViolation `json:"violations,omitempty"`
+	RetryAfterMs int64            `json:"retry_after_ms,omitempty"`
+	Cause        json.RawMessage  `json:"cause,omitempty"`
+}
+
+// MarshalJSON encodes the error and its cause chain, causes that are not an *Error
+// keep only their message. Fields and messages are redacted.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	out := errorJSON{
+		Kind:         e.Kind,
+		Op:           e.Op,
+		Message:      redactString(e.Message),
+		Code:         e.Code,
+		Fields:       redactFields(e.Fields),
+		PublicFields: redactFields(e.PublicFields),
+		Violations:   e.Violations,
+		RetryAfterMs: e.RetryAfter.Milliseconds(),
+	}
+	if e.Cause != nil {
+		var err error
+		if cause, ok := e.Cause.(*Error); ok {
+			out.Cause, err = json.Marshal(cause)
+		} else {
+			out.Cause, err = json.Marshal(map[string]string{"message": redactString(e.Cause.Error())})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var in errorJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*e = Error{
+		Kind:         in.Kind,
+		Op:           in.Op,
+		Message:      in.Message,
+		Code:         in.Code,
+		Fields:       in.Fields,
+		PublicFields: in.PublicFields,
+		Violations:   in.Violations,
+		RetryAfter:   time.Duration(in.RetryAfterMs) * time.Millisecond,
+	}
+	if len(in.Cause) == 0 || string(in.Cause) == "null" {
+		return nil
+	}
+	var probe struct {
+		Kind    *Kind  `json:"kind"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(in.Cause, &probe); err != nil {
+		return err
+	}
+	if probe.Kind == nil {
+		e.Cause = errors.New(probe.Message)
+		return nil
+	}
+	cause := &Error{}
+	if err := json.Unmarshal(in.Cause, cause); err != nil {
+		return err
+	}
+	e.Cause = cause
+	return nil
+}
+
+// FromProblem rebuilds an *Error from a problem response, taking the Kind from the
+// problem's kind member or, when missing, from its status. Extras become PublicFields.
+func FromProblem(p Problem) *Error {
+	e := &Error{Kind: KindFromHTTPStatus(p.Status), Message: p.Detail, Code: p.Code}
+	if p.Kind != "" {
+		if kind, ok := kindFromName(p.Kind); ok {
+			e.Kind = kind
+		}
+	}
+	if e.Message == "" {
+		e.Message = p.Title
+	}
+	for key, value := range p.Extras {
+		if key == "violations" {
+			if b, err := json.Marshal(value); err == nil && json.Unmarshal(b, &e.Violations) == nil {
+				continue
+			}
+		}
+		if e.PublicFields == nil {
+			e.PublicFields = map[string]any{}
+		}
+		e.PublicFields[key] = value
+	}
+	return e
+}
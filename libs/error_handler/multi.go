@@ -0,0 +1,168 @@
+package errorhandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FieldViolation describes one invalid field, Field being a path such as "items[0].quantity".
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+func WithViolations(violations ...FieldViolation) Option {
+	return func(e *Error) {
+		e.Violations = append(e.Violations, violations...)
+	}
+}
+
+// MultiError collects several errors, e.g. every failed check of a request body. It
+// unwraps like errors.Join so errors.Is and errors.As see each collected error.
+type MultiError struct {
+	Errors []error
+}
+
+// Add appends err, ignoring nil and flattening nested MultiErrors.
+func (m *MultiError) Add(err error) {
+	if err == nil {
+		return
+	}
+	if nested, ok := err.(*MultiError); ok {
+		m.Errors = append(m.Errors, nested.Errors...)
+		return
+	}
+	m.Errors = append(m.Errors, err)
+}
+
+// AddViolation records a KindInvalidArgument error for field.
+func (m *MultiError) AddViolation(field, rule, message string) {
+	m.Add(New(KindInvalidArgument, message, WithViolations(FieldViolation{Field: field, Rule: rule, Message: message})))
+}
+
+func (m *MultiError) Len() int {
+	return len(m.Errors)
+}
+
+// Err returns nil when nothing was collected, so validators can end with "return errs.Err()".
+func (m *MultiError) Err() error {
+	if m == nil || len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+	messages := make([]string, len(m.Errors))
+	for i, err := range m.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(m.Errors), strings.Join(messages, "; "))
+}
+
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
+
+// Kind is the Kind shared by every collected error. Mixed kinds resolve to KindInternal
+// when any of them is a server error and to KindInvalidArgument otherwise.
+func (m *MultiError) Kind() Kind {
+	kind := KindUnknown
+	for i, err := range m.Errors {
+		current := KindInternal
+		var e *Error
+		if errors.As(err, &e) {
+			current = e.Kind
+		}
+		if current.HttpStatus() >= http.StatusInternalServerError {
+			return KindInternal
+		}
+		if i == 0 {
+			kind = current
+		} else if current != kind {
+			kind = KindInvalidArgument
+		}
+	}
+	return kind
+}
+
+// Violations returns the field violations of every collected error.
+func (m *MultiError) Violations() []FieldViolation {
+	var violations []FieldViolation
+	for _, err := range m.Errors {
+		var e *Error
+		if errors.As(err, &e) {
+			violations = append(violations, e.Violations...)
+		}
+	}
+	return violations
+}
+
+// toProblem describes a single collected error exactly like the error itself, code and
+// catalog included. Several errors report their count, and their code when they share one.
+func (m *MultiError) toProblem(instance string, locales []string) Problem {
+	if len(m.Errors) == 1 {
+		return toProblem(m.Errors[0], instance, locales)
+	}
+	kind := m.Kind()
+	s := kind.HttpStatus()
+	detail := http.StatusText(s)
+	if len(m.Errors) > 1 {
+		detail = fmt.Sprintf("%d errors occurred", len(m.Errors))
+	}
+	return Problem{
+		Type:     problemType(kind, s),
+		Title:    http.StatusText(s),
+		Status:   s,
+		Detail:   redactString(detail),
+		Instance: instance,
+		Kind:     kind.String(),
+		Code:     m.code(),
+		Extras:   withViolations(nil, m.Violations()),
+	}
+}
+
+// code returns the Code shared by every collected error, empty when they differ.
+func (m *MultiError) code() string {
+	var code string
+	for i, err := range m.Errors {
+		var e *Error
+		if !errors.As(err, &e) || (i > 0 && e.Code != code) {
+			return ""
+		}
+		code = e.Code
+	}
+	return code
+}
+
+// asMulti reports whether err itself is an aggregate: a MultiError or an
+// errors.Join result. A MultiError wrapped by an *Error is not, so the outer
+// error keeps deciding kind, status and detail.
+func asMulti(err error) (*MultiError, bool) {
+	if multi, ok := err.(*MultiError); ok {
+		return multi, true
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return &MultiError{Errors: joined.Unwrap()}, true
+	}
+	return nil, false
+}
+
+// withViolations adds violations under the "violations" extra without changing fields.
+func withViolations(fields map[string]any, violations []FieldViolation) map[string]any {
+	if len(violations) == 0 {
+		return fields
+	}
+	extras := make(map[string]any, len(fields)+1)
+	for key, value := range fields {
+		extras[key] = value
+	}
+	extras["violations"] = violations
+	return extras
+}
@@ -0,0 +1,84 @@
+package errorhandler
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWrappedMultiErrorKeepsOuterError(t *testing.T) {
+	var errs MultiError
+	errs.AddViolation("email", "required", "email is required")
+	errs.AddViolation("name", "required", "name is required")
+	err := Wrap(KindNotFound, "order missing", errs.Err())
+
+	if got := Status(err); got != http.StatusNotFound {
+		t.Errorf("Status() = %d, want %d", got, http.StatusNotFound)
+	}
+	p := NewProblem(err, "/orders/1")
+	if p.Status != http.StatusNotFound || p.Kind != KindNotFound.String() || p.Detail != "order missing" {
+		t.Errorf("NewProblem() = %+v, want 404 %q with detail %q", p, KindNotFound.String(), "order missing")
+	}
+}
+
+func TestTopLevelAggregates(t *testing.T) {
+	var errs MultiError
+	errs.AddViolation("email", "required", "email is required")
+	errs.AddViolation("name", "required", "name is required")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"multi error", errs.Err()},
+		{"joined", errors.Join(errs.Errors...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProblem(tt.err, "")
+			if p.Status != http.StatusBadRequest || p.Detail != "2 errors occurred" {
+				t.Errorf("NewProblem() = %+v, want 400 with detail %q", p, "2 errors occurred")
+			}
+			if violations, _ := p.Extras["violations"].([]FieldViolation); len(violations) != 2 {
+				t.Errorf("violations = %#v, want 2", p.Extras["violations"])
+			}
+		})
+	}
+}
+
+func TestSingleMultiErrorMatchesError(t *testing.T) {
+	RegisterCode(CodeInfo{Code: "EMAIL_TAKEN", Kind: KindAlreadyExists, Message: "email already used", HttpStatus: http.StatusUnprocessableEntity})
+	catalog := NewCatalog("en")
+	catalog.Add("EMAIL_TAKEN", "fr", "adresse déjà utilisée")
+	SetCatalog(catalog)
+	t.Cleanup(func() { SetCatalog(nil) })
+
+	var errs MultiError
+	errs.Add(NewCode("EMAIL_TAKEN"))
+
+	want := NewProblem(errs.Errors[0], "/users", "fr")
+	if got := NewProblem(errs.Err(), "/users", "fr"); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewProblem() = %+v, want %+v", got, want)
+	}
+	if want.Code != "EMAIL_TAKEN" || want.Detail != "adresse déjà utilisée" || want.Status != http.StatusUnprocessableEntity {
+		t.Errorf("single error problem = %+v", want)
+	}
+	if got := Status(errs.Err()); got != http.StatusUnprocessableEntity {
+		t.Errorf("Status() = %d, want %d", got, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestMultiErrorSharedCode(t *testing.T) {
+	var errs MultiError
+	errs.Add(New(KindInvalidArgument, "bad email", WithCode("INVALID_FIELD")))
+	errs.Add(New(KindInvalidArgument, "bad name", WithCode("INVALID_FIELD")))
+	if got := NewProblem(errs.Err(), "").Code; got != "INVALID_FIELD" {
+		t.Errorf("Code = %q, want INVALID_FIELD", got)
+	}
+
+	errs.Add(New(KindInvalidArgument, "bad age", WithCode("OUT_OF_RANGE")))
+	if got := NewProblem(errs.Err(), "").Code; got != "" {
+		t.Errorf("Code = %q, want none for mixed codes", got)
+	}
+}
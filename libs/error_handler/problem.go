@@ -0,0 +1,97 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+const ProblemContentType = "application/problem+json"
+
+var (
+	typeURIsMu sync.RWMutex
+	typeURIs   = map[Kind]string{}
+)
+
+// SetTypeURI sets the Problem type for errors of kind, e.g. "https://example.com/problems/not-found".
+// Kinds without a URI use "about:blank#<status>".
+func SetTypeURI(kind Kind, uri string) {
+	typeURIsMu.Lock()
+	defer typeURIsMu.Unlock()
+	typeURIs[kind] = uri
+}
+
+func problemType(kind Kind, status int) string {
+	typeURIsMu.RLock()
+	uri, ok := typeURIs[kind]
+	typeURIsMu.RUnlock()
+	if ok {
+		return uri
+	}
+	return fmt.Sprintf("about:blank#%d", status)
+}
+
+// NewProblem builds the RFC 7807 representation of err for the request path instance,
+// localising coded errors in the first matching locale.
+func NewProblem(err error, instance string, locales ...string) Problem {
+	return toProblem(err, instance, locales)
+}
+
+// WriteProblem writes err as an application/problem+json response, with a Retry-After
+// header when the error carries a RetryAfter hint. Details of coded errors are localised
+// from the request's Accept-Language, see SetCatalog.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	instance := ""
+	var locales []string
+	if r != nil {
+		if r.URL != nil {
+			instance = r.URL.Path
+		}
+		locales = ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	}
+	p := toProblem(err, instance, locales)
+	b, _ := json.Marshal(p)
+	w.Header().Set("Content-Type", ProblemContentType)
+	if d, ok := RetryAfter(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+	}
+	w.WriteHeader(p.Status)
+	w.Write(b)
+}
+
+// HandlerFunc is an http.Handler that returns its error instead of writing it.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteProblem(w, r, err)
+	}
+}
+
+// Recover converts panics in next into KindInternal problem responses.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				WriteProblem(w, r, FromPanic(recovered))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FromPanic turns a recovered value into a KindInternal Error with the panic site stack.
+// The panic value is kept as the cause so it is logged but not sent to clients.
+func FromPanic(recovered any) error {
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("%v", recovered)
+	}
+	return Wrap(KindInternal, "panic recovered", err, WithOp("errorhandler.Recover"), WithStack())
+}
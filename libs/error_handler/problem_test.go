@@ -0,0 +1,72 @@
+package errorhandler
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProblemExtras(t *testing.T) {
+	err := New(KindInvalidArgument, "bad order",
+		WithFields(map[string]any{"query": "SELECT * FROM orders WHERE id = $1", "args": []any{7}}),
+		WithPublicFields(map[string]any{"order_id": 7, "token": "s3cr3t"}),
+		WithViolations(FieldViolation{Field: "qty", Rule: "min", Message: "qty must be positive"}),
+	)
+
+	p := NewProblem(err, "/orders")
+	if _, ok := p.Extras["query"]; ok {
+		t.Errorf("internal field leaked into extras: %v", p.Extras)
+	}
+	if _, ok := p.Extras["args"]; ok {
+		t.Errorf("internal field leaked into extras: %v", p.Extras)
+	}
+	if p.Extras["order_id"] != 7 || p.Extras["token"] == "s3cr3t" {
+		t.Errorf("public extras = %v, want order_id and a redacted token", p.Extras)
+	}
+	if violations, _ := p.Extras["violations"].([]FieldViolation); len(violations) != 1 {
+		t.Errorf("violations = %#v, want 1", p.Extras["violations"])
+	}
+
+	if p := NewProblem(New(KindInternal, "boom", WithFields(map[string]any{"sql": "DROP"})), ""); p.Extras != nil {
+		t.Errorf("extras without public fields = %v, want none", p.Extras)
+	}
+
+	back := FromProblem(NewProblem(New(KindNotFound, "missing", WithPublicFields(map[string]any{"id": "a1"})), ""))
+	if back.Kind != KindNotFound || !reflect.DeepEqual(back.PublicFields, map[string]any{"id": "a1"}) || back.Fields != nil {
+		t.Errorf("FromProblem() = %+v, want the extras as public fields", back)
+	}
+}
+
+func TestSetTypeURI(t *testing.T) {
+	t.Cleanup(func() {
+		typeURIsMu.Lock()
+		delete(typeURIs, KindNotFound)
+		typeURIsMu.Unlock()
+	})
+	err := New(KindNotFound, "missing")
+	if got := NewProblem(err, "").Type; got != "about:blank#404" {
+		t.Errorf("default type = %q", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetTypeURI(KindNotFound, "https://example.com/problems/not-found")
+		}()
+		go func() {
+			defer wg.Done()
+			WriteProblem(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), err)
+		}()
+	}
+	wg.Wait()
+
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, httptest.NewRequest("GET", "/users/1", nil), err)
+	if body := rec.Body.String(); !strings.Contains(body, `"type":"https://example.com/problems/not-found"`) || rec.Code != 404 {
+		t.Errorf("WriteProblem() = %d %s", rec.Code, body)
+	}
+}
@@ -0,0 +1,96 @@
+package errorhandler
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// WithRetryable overrides the Kind based classification done by Retryable.
+func WithRetryable(retryable bool) Option {
+	return func(e *Error) {
+		e.retryable = &retryable
+	}
+}
+
+func WithRetryAfter(d time.Duration) Option {
+	return func(e *Error) {
+		e.RetryAfter = d
+	}
+}
+
+var retryableKinds = map[Kind]bool{
+	KindUnavailable:       true,
+	KindAborted:           true,
+	KindResourceExhausted: true,
+	KindDeadlineExceeded:  true,
+}
+
+// Postgres SQLSTATEs worth retrying; class 08 (connection exception) is matched by prefix.
+var retryableSQLStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"53300": true, // too_many_connections
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// Retryable reports whether err is worth retrying. An explicit WithRetryable wins, then
+// transient causes (timeouts, reset or refused connections, bad driver connections,
+// serialization failures and deadlocks) and finally the Kind of the outermost Error.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if e, ok := current.(*Error); ok && e.retryable != nil {
+			return *e.retryable
+		}
+	}
+	if transient(err) {
+		return true
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return retryableKinds[e.Kind]
+	}
+	return false
+}
+
+// RetryAfter returns the first RetryAfter hint found in err's chain.
+func RetryAfter(err error) (time.Duration, bool) {
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if e, ok := current.(*Error); ok && e.RetryAfter > 0 {
+			return e.RetryAfter, true
+		}
+	}
+	return 0, false
+}
+
+func transient(err error) bool {
+	switch {
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE):
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var sqlErr interface{ SQLState() string }
+	if errors.As(err, &sqlErr) {
+		state := sqlErr.SQLState()
+		return retryableSQLStates[state] || strings.HasPrefix(state, "08")
+	}
+	return false
+}
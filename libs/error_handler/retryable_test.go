@@ -0,0 +1,103 @@
+package errorhandler
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pq: " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryableKinds(t *testing.T) {
+	want := map[Kind]bool{
+		KindUnavailable:       true,
+		KindAborted:           true,
+		KindResourceExhausted: true,
+		KindDeadlineExceeded:  true,
+	}
+	for kind := range kindNames {
+		if got := Retryable(New(kind, "failed")); got != want[kind] {
+			t.Errorf("Retryable(%v) = %v, want %v", kind, got, want[kind])
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("boom"), false},
+		{"override true", New(KindInvalidArgument, "bad", WithRetryable(true)), true},
+		{"override false", New(KindUnavailable, "down", WithRetryable(false)), false},
+		{"inner override wins over outer kind", Wrap(KindUnavailable, "down", New(KindInternal, "bug", WithRetryable(false))), false},
+		{"wrapped retryable kind", fmt.Errorf("call: %w", New(KindUnavailable, "down")), true},
+		{"net timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, true},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"os deadline", os.ErrDeadlineExceeded, true},
+		{"connection reset", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"broken pipe", syscall.EPIPE, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"bad conn", driver.ErrBadConn, true},
+		{"transient cause under internal kind", Wrap(KindInternal, "query failed", driver.ErrBadConn), true},
+		{"serialization failure", sqlStateError("40001"), true},
+		{"deadlock", sqlStateError("40P01"), true},
+		{"too many connections", sqlStateError("53300"), true},
+		{"admin shutdown", sqlStateError("57P01"), true},
+		{"connection exception class", sqlStateError("08006"), true},
+		{"unique violation", sqlStateError("23505"), false},
+		{"canceled", context.Canceled, false},
+		{"wrapped canceled", Wrap(KindUnavailable, "aborted", context.Canceled), false},
+		{"canceled beats override", Wrap(KindUnavailable, "aborted", context.Canceled, WithRetryable(true)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Retryable(tt.err); got != tt.want {
+				t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+		ok   bool
+	}{
+		{"nil", nil, 0, false},
+		{"no hint", New(KindUnavailable, "down"), 0, false},
+		{"hint", New(KindResourceExhausted, "slow down", WithRetryAfter(2*time.Second)), 2 * time.Second, true},
+		{"wrapped hint", fmt.Errorf("call: %w", New(KindUnavailable, "down", WithRetryAfter(time.Second))), time.Second, true},
+		{"outer hint first", Wrap(KindUnavailable, "down", New(KindUnavailable, "inner", WithRetryAfter(time.Second)), WithRetryAfter(3*time.Second)), 3 * time.Second, true},
+		{"inner hint", Wrap(KindUnavailable, "down", New(KindUnavailable, "inner", WithRetryAfter(time.Second))), time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RetryAfter(tt.err)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("RetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package errorhandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+)
+
+var (
+	stackDepth     atomic.Int64
+	autoStackKinds atomic.Pointer[map[Kind]bool]
+)
+
+func init() {
+	stackDepth.Store(10)
+	CaptureStackFor(KindInternal)
+}
+
+// SetStackDepth changes how many frames WithStack and automatic capture record.
+func SetStackDepth(depth int) {
+	if depth > 0 {
+		stackDepth.Store(int64(depth))
+	}
+}
+
+// CaptureStackFor makes New and Wrap record a stack for errors of the given kinds even
+// without WithStack. KindInternal is captured by default; call it with no kinds to disable.
+func CaptureStackFor(kinds ...Kind) {
+	next := make(map[Kind]bool, len(kinds))
+	for _, kind := range kinds {
+		next[kind] = true
+	}
+	autoStackKinds.Store(&next)
+}
+
+func captureStack(kind Kind) bool {
+	return (*autoStackKinds.Load())[kind]
+}
+
+// callers skips runtime.Callers, callers itself and skip more frames.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, stackDepth.Load())
+	return pcs[:runtime.Callers(skip+2, pcs)]
+}
+
+// StackTrace resolves the captured program counters, nil when no stack was captured.
+func (e *Error) StackTrace() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(e.stack)
+	var trace []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, frame)
+		if !more {
+			return trace
+		}
+	}
+}
+
+// Format prints Error() for %s and %v. %+v prints one line per wrapped error followed
+// by the innermost captured stack trace. Other verbs are reported the way fmt reports
+// a bad verb, e.g. "%!d(*errorhandler.Error=[op] message)".
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.formatVerbose(s)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, "%!"+string(verb)+"(*errorhandler.Error="+e.Error()+")")
+	}
+}
+
+func (e *Error) formatVerbose(w io.Writer) {
+	var stack *Error
+	prefix := ""
+	for current := error(e); current != nil; current = errors.Unwrap(current) {
+		link, ok := current.(*Error)
+		if !ok {
+			if _, wrapped := current.(interface{ Unwrap() error }); wrapped {
+				continue
+			}
+			fmt.Fprintf(w, "%s%s", prefix, current.Error())
+			break
+		}
+		fmt.Fprintf(w, "%s[%s] %s (%s)", prefix, link.Op, link.Message, link.Kind)
+		if len(link.Fields) > 0 {
+			fmt.Fprintf(w, " %v", redactFields(link.Fields))
+		}
+		if len(link.stack) > 0 {
+			stack = link
+		}
+		prefix = "\ncaused by: "
+	}
+	if stack == nil {
+		return
+	}
+	for _, frame := range stack.StackTrace() {
+		fmt.Fprintf(w, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
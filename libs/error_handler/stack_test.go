@@ -0,0 +1,126 @@
+package errorhandler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newStackError() error {
+	return New(KindInvalidArgument, "bad input", WithOp("stack.newStackError"), WithStack())
+}
+
+func TestStackCapture(t *testing.T) {
+	t.Cleanup(func() { CaptureStackFor(KindInternal) })
+
+	tests := []struct {
+		name  string
+		err   func() error
+		stack bool
+	}{
+		{"WithStack", newStackError, true},
+		{"internal by default", func() error { return New(KindInternal, "bug") }, true},
+		{"wrapped internal by default", func() error { return Wrap(KindInternal, "bug", errors.New("cause")) }, true},
+		{"other kinds", func() error { return New(KindNotFound, "missing") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.err().(*Error)
+			if got := len(e.Callers()) > 0; got != tt.stack {
+				t.Fatalf("captured = %v, want %v", got, tt.stack)
+			}
+			if !tt.stack {
+				if e.StackTrace() != nil {
+					t.Errorf("StackTrace() = %v, want nil", e.StackTrace())
+				}
+				return
+			}
+			// The first frame is the caller of New or Wrap, not errutil.go.
+			if frame := e.StackTrace()[0]; !strings.HasSuffix(frame.File, "stack_test.go") {
+				t.Errorf("first frame = %s %s:%d", frame.Function, frame.File, frame.Line)
+			}
+		})
+	}
+
+	CaptureStackFor(KindNotFound)
+	if len(New(KindNotFound, "missing").(*Error).Callers()) == 0 {
+		t.Error("CaptureStackFor(KindNotFound) did not capture")
+	}
+	if len(New(KindInternal, "bug").(*Error).Callers()) != 0 {
+		t.Error("KindInternal still captured after CaptureStackFor(KindNotFound)")
+	}
+	CaptureStackFor()
+	if len(New(KindInternal, "bug").(*Error).Callers()) != 0 {
+		t.Error("CaptureStackFor() did not disable capture")
+	}
+}
+
+func TestSetStackDepth(t *testing.T) {
+	t.Cleanup(func() { SetStackDepth(10) })
+	SetStackDepth(1)
+	if got := len(newStackError().(*Error).Callers()); got != 1 {
+		t.Errorf("frames = %d, want 1", got)
+	}
+	SetStackDepth(0)
+	if got := len(newStackError().(*Error).Callers()); got != 1 {
+		t.Errorf("SetStackDepth(0) changed the depth, frames = %d", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			SetStackDepth(i)
+		}()
+		go func() {
+			defer wg.Done()
+			CaptureStackFor(KindInternal, KindUnavailable)
+		}()
+		go func() {
+			defer wg.Done()
+			New(KindInternal, "bug")
+		}()
+	}
+	wg.Wait()
+	CaptureStackFor(KindInternal)
+}
+
+func TestFormat(t *testing.T) {
+	err := Wrap(KindUnavailable, "fetch failed", fmt.Errorf("dial: %w", newStackError()), WithOp("users.Fetch"), WithFields(map[string]any{"password": "hunter2"}))
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", err.Error()},
+		{"%v", err.Error()},
+		{"%q", fmt.Sprintf("%q", err.Error())},
+		{"%d", "%!d(*errorhandler.Error=" + err.Error() + ")"},
+		{"%x", "%!x(*errorhandler.Error=" + err.Error() + ")"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, err); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	verbose := fmt.Sprintf("%+v", err)
+	lines := strings.Split(verbose, "\n")
+	if lines[0] != "[users.Fetch] fetch failed (unavailable) map[password:[REDACTED]]" {
+		t.Errorf("first line = %q", lines[0])
+	}
+	if lines[1] != "caused by: [stack.newStackError] bad input (invalid_argument)" {
+		t.Errorf("second line = %q", lines[1])
+	}
+	if len(lines) < 4 || !strings.HasSuffix(lines[2], "/error_handler.newStackError") || !strings.Contains(lines[3], "stack_test.go:") {
+		t.Errorf("%%+v stack = %q, want the newStackError frame", verbose)
+	}
+
+	plain := fmt.Sprintf("%+v", Wrap(KindNotFound, "missing", errors.New("no rows"), WithOp("db.Get")))
+	if plain != "[db.Get] missing (not_found)\ncaused by: no rows" {
+		t.Errorf("%%+v without stack = %q", plain)
+	}
+}
@@ -1,43 +1,107 @@
 package fl
 
 import (
-	applicationpackage "github.com/Arthur-Conti/guh/libs/log/application_package"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
 	"github.com/Arthur-Conti/guh/libs/log/logger"
-	"github.com/Arthur-Conti/guh/libs/log/outputs"
 )
 
-func Log(message string) {
-	plainOpts := outputs.PlainOutputOpts{
-		DebugPattern:   "DEBUG: ",
-		WarningPattern: "WARNING: ",
-		InfoPattern:    "INFO: ",
-		ErrorPattern:   "ERROR: ",
+const (
+	LevelEnv  = logger.LevelEnv
+	FormatEnv = logger.FormatEnv
+)
+
+const applicationPackage = "fast_logger"
+
+var (
+	current  atomic.Pointer[logger.Logger]
+	initOnce sync.Once
+)
+
+// Default returns the package-level logger, building it from GUH_LOG_LEVEL (level spec,
+// default "info") and GUH_LOG_FORMAT ("plain" or "json") on first use.
+func Default() *logger.Logger {
+	initOnce.Do(func() {
+		current.CompareAndSwap(nil, NewFromEnv())
+	})
+	return current.Load()
+}
+
+// SetDefault replaces the package-level logger, usually with the application's one,
+// and makes it the fallback of logger.FromContext as well. nil is ignored.
+func SetDefault(l *logger.Logger) {
+	if l == nil {
+		return
 	}
-	plainOutput := outputs.NewPlainOutput(plainOpts)
-	appPackage := applicationpackage.NewPackageLevel()
-	loggerOpts := logger.LoggerOpts{
-		OutputType:         plainOutput,
-		LevelStr:           "debug",
-		ApplicationPackage: *appPackage,
+	initOnce.Do(func() {})
+	current.Store(l)
+	logger.SetDefault(l)
+}
+
+func NewFromEnv() *logger.Logger {
+	l, err := logger.NewFromConfig(logger.ConfigFromEnv(logger.Config{}))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fl: invalid logger environment, using defaults: %v\n", err)
+		l, _ = logger.NewFromConfig(logger.Config{})
 	}
-	l := logger.NewLogger(loggerOpts)
-	l.Debug(logger.LogMessage{ApplicationPackage: "fast_logger", Message: message})
+	return l
+}
+
+// Log logs at info so existing calls show up with the default level, use Debug for
+// debug output.
+func Log(message string) {
+	logAt(loglevels.InfoLevel, message)
 }
 
 func Logf(message string, args ...any) {
-	plainOpts := outputs.PlainOutputOpts{
-		DebugPattern:   "DEBUG: ",
-		WarningPattern: "WARNING: ",
-		InfoPattern:    "INFO: ",
-		ErrorPattern:   "ERROR: ",
-	}
-	plainOutput := outputs.NewPlainOutput(plainOpts)
-	appPackage := applicationpackage.NewPackageLevel()
-	loggerOpts := logger.LoggerOpts{
-		OutputType:         plainOutput,
-		LevelStr:           "debug",
-		ApplicationPackage: *appPackage,
-	}
-	l := logger.NewLogger(loggerOpts)
-	l.Debugf(logger.LogMessage{ApplicationPackage: "fast_logger", Message: message, Vals: args})
-}
\ No newline at end of file
+	logAt(loglevels.InfoLevel, message, args...)
+}
+
+func Trace(message string) {
+	logAt(loglevels.TraceLevel, message)
+}
+
+func Tracef(message string, args ...any) {
+	logAt(loglevels.TraceLevel, message, args...)
+}
+
+func Debug(message string) {
+	logAt(loglevels.DebugLevel, message)
+}
+
+func Debugf(message string, args ...any) {
+	logAt(loglevels.DebugLevel, message, args...)
+}
+
+func Info(message string) {
+	logAt(loglevels.InfoLevel, message)
+}
+
+func Infof(message string, args ...any) {
+	logAt(loglevels.InfoLevel, message, args...)
+}
+
+func Warn(message string) {
+	logAt(loglevels.WarningLevel, message)
+}
+
+func Warnf(message string, args ...any) {
+	logAt(loglevels.WarningLevel, message, args...)
+}
+
+func Error(message string) {
+	logAt(loglevels.ErrorLevel, message)
+}
+
+func Errorf(message string, args ...any) {
+	logAt(loglevels.ErrorLevel, message, args...)
+}
+
+func logAt(level loglevels.LogLevel, message string, args ...any) {
+	// Skip logAt and the exported fl function so the caller is the user's code.
+	Default().LogAt(2, level, logger.LogMessage{ApplicationPackage: applicationPackage, Message: message, Vals: args})
+}
@@ -0,0 +1,47 @@
+package fl
+
+import (
+	"path/filepath"
+	"testing"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/logger"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+	"github.com/Arthur-Conti/guh/libs/log/outputs/outputstest"
+)
+
+func TestPackageFunctions(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	SetDefault(logger.NewLogger(logger.LoggerOpts{OutputType: out, Level: loglevels.InfoLevel}))
+
+	Log("plain message")
+	Logf("formatted %d", 7)
+	Debug("hidden at info")
+	Warnf("slow %s", "query")
+
+	outputstest.AssertLogged(t, out, loglevels.InfoLevel, "plain message")
+	outputstest.AssertLogged(t, out, loglevels.InfoLevel, "formatted 7")
+	outputstest.AssertLogged(t, out, loglevels.WarningLevel, "slow query")
+	outputstest.AssertNotLogged(t, out, loglevels.DebugLevel, "hidden")
+
+	entry := out.Entries()[0]
+	if entry.Package != applicationPackage {
+		t.Errorf("Package = %q, want %q", entry.Package, applicationPackage)
+	}
+	if frame, ok := entry.Caller(); !ok || filepath.Base(frame.File) != "fast_logger_test.go" {
+		t.Errorf("caller = %s, want this test file", frame.File)
+	}
+}
+
+func TestSetDefaultIgnoresNil(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := logger.NewLogger(logger.LoggerOpts{OutputType: out})
+	SetDefault(l)
+	SetDefault(nil)
+
+	if Default() != l {
+		t.Fatal("SetDefault(nil) replaced the default logger")
+	}
+	Info("still logging")
+	outputstest.AssertLogged(t, out, loglevels.InfoLevel, "still logging")
+}
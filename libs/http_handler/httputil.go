@@ -3,23 +3,46 @@ package httphandler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	"github.com/Arthur-Conti/guh/libs/ratelimit"
 )
 
 type HttpHandler struct {
 	client http.Client
 }
 
+type HttpHandlerOpts struct {
+	// Transport wraps outgoing requests, e.g. circuitbreaker.NewTransport; defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	Timeout   time.Duration
+	// RateLimiter, when set, delays requests to stay within a quota.
+	RateLimiter ratelimit.Limiter
+}
+
 func NewHttpHandler() * HttpHandler {
 	return &HttpHandler{
 		client: http.Client{},
 	}
 }
 
+func NewHttpHandlerWithOpts(opts HttpHandlerOpts) *HttpHandler {
+	transport := opts.Transport
+	if opts.RateLimiter != nil {
+		transport = ratelimit.NewTransport(opts.RateLimiter, transport)
+	}
+	return &HttpHandler{
+		client: http.Client{Transport: transport, Timeout: opts.Timeout},
+	}
+}
+
 func (hh *HttpHandler) Request(method, url string, body, result any) error {
 	var bodyReader io.Reader
 	if body != nil {
@@ -38,13 +61,12 @@ func (hh *HttpHandler) Request(method, url string, body, result any) error {
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := hh.client.Do(req)
 	if err != nil {
-		return errorhandler.Wrap(errorhandler.KindInternal, "Error sending get message to "+url, err, errorhandler.WithOp("httphandler.Request"), errorhandler.WithFields(map[string]any{"method": method, "url": url, "body": body}))
+		return errorhandler.Wrap(transportKind(err), "Error sending get message to "+url, err, errorhandler.WithOp("httphandler.Request"), errorhandler.WithFields(map[string]any{"method": method, "url": url, "body": body}))
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return errorhandler.New(errorhandler.KindInternal, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(b)), errorhandler.WithOp("httphandler.Request"), errorhandler.WithFields(map[string]any{"method": method, "url": url, "body": body}))
+		return responseError(resp, map[string]any{"method": method, "url": url, "body": body, "status": resp.StatusCode})
 	}
 	
 	if resp.Body != nil {
@@ -56,4 +78,58 @@ func (hh *HttpHandler) Request(method, url string, body, result any) error {
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// transportKind keeps the Kind of errors raised by the transport (e.g. an open circuit
+// breaker) and reports other failures to reach the server as KindUnavailable.
+func transportKind(err error) errorhandler.Kind {
+	var e *errorhandler.Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return errorhandler.KindUnavailable
+}
+
+// responseError decodes problem+json and errorhandler.Error JSON bodies so the remote
+// Kind, code and violations survive; other bodies get a Kind from the status code.
+func responseError(resp *http.Response, fields map[string]any) error {
+	b, _ := io.ReadAll(resp.Body)
+	var remote *errorhandler.Error
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case errorhandler.ProblemContentType:
+		var p errorhandler.Problem
+		if err := json.Unmarshal(b, &p); err == nil {
+			if p.Status == 0 {
+				p.Status = resp.StatusCode
+			}
+			remote = errorhandler.FromProblem(p)
+		}
+	case "application/json":
+		var e errorhandler.Error
+		if err := json.Unmarshal(b, &e); err == nil && e.Message != "" {
+			remote = &e
+		}
+	}
+	if remote == nil {
+		return errorhandler.New(errorhandler.KindFromHTTPStatus(resp.StatusCode), fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(b)), errorhandler.WithOp("httphandler.Request"), errorhandler.WithFields(fields), errorhandler.WithRetryAfter(retryAfter(resp)))
+	}
+	if remote.Kind == errorhandler.KindUnknown {
+		remote.Kind = errorhandler.KindFromHTTPStatus(resp.StatusCode)
+	}
+	return errorhandler.Wrap(remote.Kind, fmt.Sprintf("HTTP %d", resp.StatusCode), remote, errorhandler.WithOp("httphandler.Request"), errorhandler.WithFields(fields), errorhandler.WithCode(remote.Code), errorhandler.WithViolations(remote.Violations...), errorhandler.WithRetryAfter(retryAfter(resp)))
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+func TestRequestErrors(t *testing.T) {
+	remote := errorhandler.New(errorhandler.KindNotFound, "order missing", errorhandler.WithOp("orders.Get"), errorhandler.WithCode("ORDER_NOT_FOUND"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/problem":
+			var errs errorhandler.MultiError
+			errs.AddViolation("email", "required", "email is required")
+			errorhandler.WriteProblem(w, r, errs.Err())
+		case "/error":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(remote)
+		case "/message":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"user not found"}`))
+		case "/text":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("busy"))
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path       string
+		kind       errorhandler.Kind
+		status     int
+		code       string
+		violations int
+		retryAfter time.Duration
+		message    string
+	}{
+		{path: "/problem", kind: errorhandler.KindInvalidArgument, status: http.StatusBadRequest, violations: 1, message: "[httphandler.Request] HTTP 400: [] email is required"},
+		{path: "/error", kind: errorhandler.KindNotFound, status: http.StatusNotFound, code: "ORDER_NOT_FOUND", message: "[httphandler.Request] HTTP 409: [orders.Get] order missing"},
+		{path: "/message", kind: errorhandler.KindNotFound, status: http.StatusNotFound, message: "[httphandler.Request] HTTP 404: [] user not found"},
+		{path: "/text", kind: errorhandler.KindUnavailable, status: http.StatusServiceUnavailable, retryAfter: 3 * time.Second, message: "[httphandler.Request] HTTP 503: busy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := NewHttpHandler().Request(http.MethodGet, srv.URL+tt.path, nil, nil)
+			e, ok := err.(*errorhandler.Error)
+			if !ok {
+				t.Fatalf("Request() error = %T %v, want *errorhandler.Error", err, err)
+			}
+			if e.Kind != tt.kind || errorhandler.Status(err) != tt.status {
+				t.Errorf("kind = %v status = %d, want %v %d", e.Kind, errorhandler.Status(err), tt.kind, tt.status)
+			}
+			if e.Code != tt.code || len(e.Violations) != tt.violations {
+				t.Errorf("code = %q violations = %v, want %q and %d violations", e.Code, e.Violations, tt.code, tt.violations)
+			}
+			if got, _ := errorhandler.RetryAfter(err); got != tt.retryAfter {
+				t.Errorf("RetryAfter = %v, want %v", got, tt.retryAfter)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
@@ -1,16 +1,133 @@
 package applicationpackage
 
-import "strings"
+import (
+	"hash/fnv"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
-type PackageLevel struct{}
+type TagFormat int
+
+const (
+	// ParenthesesFormat renders "(Db.Migrate) ".
+	ParenthesesFormat TagFormat = iota
+	// BracketsFormat renders "[Db.Migrate] ".
+	BracketsFormat
+	// PlainFormat renders "Db.Migrate: ".
+	PlainFormat
+)
+
+var packageColors = []string{
+	"\033[34m", "\033[35m", "\033[36m", "\033[32m", "\033[33m",
+	"\033[94m", "\033[95m", "\033[96m", "\033[92m", "\033[93m",
+}
+
+type PackageLevelOpts struct {
+	Format TagFormat
+	// Width pads the tag to a fixed number of characters so messages line up.
+	Width int
+	// Color gives every top-level package a stable colour hashed from its name. Outputs
+	// only apply it when they colour their own output, see outputs.ColorMode.
+	Color bool
+	// KeepCase disables capitalising each dot separated segment.
+	KeepCase bool
+	// AutoDetect uses the caller's Go package when a message has no ApplicationPackage.
+	AutoDetect bool
+}
+
+type PackageLevel struct {
+	opts PackageLevelOpts
+}
 
 func NewPackageLevel() *PackageLevel {
 	return &PackageLevel{}
 }
 
+func NewPackageLevelWithOpts(opts PackageLevelOpts) *PackageLevel {
+	return &PackageLevel{opts: opts}
+}
+
+func (pl *PackageLevel) AutoDetect() bool {
+	return pl.opts.AutoDetect
+}
+
 func (pl *PackageLevel) Style(name string) string {
 	if name == "" {
 		return ""
 	}
-	return "(" + strings.ToUpper(name[:1]) + name[1:] + ") "
+	if !pl.opts.KeepCase {
+		name = capitalize(name)
+	}
+	var tag string
+	switch pl.opts.Format {
+	case BracketsFormat:
+		tag = "[" + name + "]"
+	case PlainFormat:
+		tag = name + ":"
+	default:
+		tag = "(" + name + ")"
+	}
+	if n := pl.opts.Width - utf8.RuneCountInString(tag); n > 0 {
+		tag += strings.Repeat(" ", n)
+	}
+	return tag + " "
+}
+
+// Color returns the ANSI colour of name's tag, empty when colours are disabled.
+func (pl *PackageLevel) Color(name string) string {
+	if name == "" || !pl.opts.Color {
+		return ""
+	}
+	return packageColor(name)
+}
+
+// Parents returns the hierarchy of name from the most to the least specific, e.g.
+// "db.migrate" gives ["db.migrate", "db"].
+func Parents(name string) []string {
+	parents := []string{name}
+	for {
+		i := strings.LastIndex(name, ".")
+		if i <= 0 {
+			return parents
+		}
+		name = name[:i]
+		parents = append(parents, name)
+	}
+}
+
+// FromPC returns the name of the Go package the function at pc belongs to.
+func FromPC(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func capitalize(name string) string {
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		r, size := utf8.DecodeRuneInString(segment)
+		if r == utf8.RuneError {
+			continue
+		}
+		segments[i] = string(unicode.ToUpper(r)) + segment[size:]
+	}
+	return strings.Join(segments, ".")
+}
+
+func packageColor(name string) string {
+	top, _, _ := strings.Cut(strings.ToLower(name), ".")
+	h := fnv.New32a()
+	h.Write([]byte(top))
+	return packageColors[h.Sum32()%uint32(len(packageColors))]
 }
@@ -0,0 +1,29 @@
+package applicationpackage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStyleHasNoColor(t *testing.T) {
+	pl := NewPackageLevelWithOpts(PackageLevelOpts{Format: BracketsFormat, Width: 8, Color: true})
+	if got := pl.Style("db.migrate"); got != "[Db.Migrate] " {
+		t.Errorf("Style() = %q, want %q", got, "[Db.Migrate] ")
+	}
+	if got := pl.Style("db"); got != "[Db]     " {
+		t.Errorf("Style() = %q, want %q", got, "[Db]     ")
+	}
+}
+
+func TestColor(t *testing.T) {
+	pl := NewPackageLevelWithOpts(PackageLevelOpts{Color: true})
+	if got := pl.Color("db.migrate"); !strings.HasPrefix(got, "\033[") || got != pl.Color("db") {
+		t.Errorf("Color() = %q, want the colour of the top-level package %q", got, pl.Color("db"))
+	}
+	if got := pl.Color(""); got != "" {
+		t.Errorf("Color(\"\") = %q, want none", got)
+	}
+	if got := NewPackageLevel().Color("db"); got != "" {
+		t.Errorf("Color() without the option = %q, want none", got)
+	}
+}
@@ -1,10 +1,133 @@
 package loglevels
 
-type LogLevel string
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LogLevel is ordered from the most verbose (TraceLevel) to the most severe (FatalLevel).
+type LogLevel int32
 
-var (
-	DebugLevel    LogLevel = "debug"
-	WarningLevel LogLevel = "warning"
-	InfoLevel    LogLevel = "info"
-	ErrorLevel   LogLevel = "error"
+const (
+	TraceLevel LogLevel = iota
+	DebugLevel
+	InfoLevel
+	WarningLevel
+	ErrorLevel
+	FatalLevel
 )
+
+var levelNames = map[LogLevel]string{
+	TraceLevel:   "trace",
+	DebugLevel:   "debug",
+	InfoLevel:    "info",
+	WarningLevel: "warning",
+	ErrorLevel:   "error",
+	FatalLevel:   "fatal",
+}
+
+var levelAliases = map[string]LogLevel{
+	"warn": WarningLevel,
+	"err":  ErrorLevel,
+}
+
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int32(l))
+}
+
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+func ParseLevel(s string) (LogLevel, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for level, levelName := range levelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	if level, ok := levelAliases[name]; ok {
+		return level, nil
+	}
+	return InfoLevel, fmt.Errorf("unknown log level %q", s)
+}
+
+// ParseLevelSpec parses specs like "info,db=debug,cli=warning". The entry without a
+// package sets the default level; when it is missing, or the spec is invalid, the returned
+// default is defaultLevel.
+func ParseLevelSpec(spec string, defaultLevel LogLevel) (LogLevel, map[string]LogLevel, error) {
+	previous := defaultLevel
+	overrides := map[string]LogLevel{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pkg, levelStr, found := strings.Cut(part, "=")
+		if !found {
+			level, err := ParseLevel(pkg)
+			if err != nil {
+				return previous, nil, err
+			}
+			defaultLevel = level
+			continue
+		}
+		pkg = strings.ToLower(strings.TrimSpace(pkg))
+		if pkg == "" {
+			return previous, nil, fmt.Errorf("missing package name in %q", part)
+		}
+		level, err := ParseLevel(levelStr)
+		if err != nil {
+			return previous, nil, fmt.Errorf("package %q: %w", pkg, err)
+		}
+		overrides[pkg] = level
+	}
+	return defaultLevel, overrides, nil
+}
+
+func (l LogLevel) SlogLevel() slog.Level {
+	switch l {
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarningLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+func FromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarningLevel
+	case level < slog.LevelError+4:
+		return ErrorLevel
+	default:
+		return FatalLevel
+	}
+}
@@ -0,0 +1,113 @@
+package loglevels
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestOrdering(t *testing.T) {
+	ordered := []LogLevel{TraceLevel, DebugLevel, InfoLevel, WarningLevel, ErrorLevel, FatalLevel}
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i-1] >= ordered[i] {
+			t.Errorf("%v >= %v, want levels ordered from trace to fatal", ordered[i-1], ordered[i])
+		}
+		if ordered[i-1].SlogLevel() >= ordered[i].SlogLevel() {
+			t.Errorf("slog level of %v >= %v", ordered[i-1], ordered[i])
+		}
+		if got := FromSlogLevel(ordered[i].SlogLevel()); got != ordered[i] {
+			t.Errorf("FromSlogLevel(%v.SlogLevel()) = %v", ordered[i], got)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LogLevel
+		wantErr bool
+	}{
+		{"trace", TraceLevel, false},
+		{"DEBUG", DebugLevel, false},
+		{" Info ", InfoLevel, false},
+		{"warning", WarningLevel, false},
+		{"WARN", WarningLevel, false},
+		{"err", ErrorLevel, false},
+		{"Fatal", FatalLevel, false},
+		{"verbose", InfoLevel, true},
+		{"", InfoLevel, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	var level LogLevel
+	if err := level.UnmarshalText([]byte("error")); err != nil || level != ErrorLevel {
+		t.Errorf("UnmarshalText(error) = %v, %v", level, err)
+	}
+	if text, _ := WarningLevel.MarshalText(); string(text) != "warning" {
+		t.Errorf("MarshalText() = %q, want warning", text)
+	}
+}
+
+func TestParseLevelSpec(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		wantLevel     LogLevel
+		wantOverrides map[string]LogLevel
+		wantErr       bool
+	}{
+		{"empty keeps the default", "", WarningLevel, map[string]LogLevel{}, false},
+		{"level only", "debug", DebugLevel, map[string]LogLevel{}, false},
+		{"overrides only", "db=trace", WarningLevel, map[string]LogLevel{"db": TraceLevel}, false},
+		{
+			name:          "level and overrides",
+			spec:          " INFO , DB.Migrate = Debug,cli=warn,",
+			wantLevel:     InfoLevel,
+			wantOverrides: map[string]LogLevel{"db.migrate": DebugLevel, "cli": WarningLevel},
+		},
+		{"unknown level", "loud", WarningLevel, nil, true},
+		{"unknown package level", "info,db=loud", WarningLevel, nil, true},
+		{"missing package", "info,=debug", WarningLevel, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, overrides, err := ParseLevelSpec(tt.spec, WarningLevel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if level != WarningLevel {
+					t.Errorf("level on error = %v, want the previous %v", level, WarningLevel)
+				}
+				return
+			}
+			if level != tt.wantLevel || !reflect.DeepEqual(overrides, tt.wantOverrides) {
+				t.Errorf("ParseLevelSpec(%q) = %v, %v, want %v, %v", tt.spec, level, overrides, tt.wantLevel, tt.wantOverrides)
+			}
+		})
+	}
+}
+
+func TestFromSlogLevel(t *testing.T) {
+	tests := []struct {
+		in   slog.Level
+		want LogLevel
+	}{
+		{slog.LevelDebug - 8, TraceLevel},
+		{slog.LevelDebug, DebugLevel},
+		{slog.LevelInfo + 1, InfoLevel},
+		{slog.LevelWarn, WarningLevel},
+		{slog.LevelError, ErrorLevel},
+		{slog.LevelError + 8, FatalLevel},
+	}
+	for _, tt := range tests {
+		if got := FromSlogLevel(tt.in); got != tt.want {
+			t.Errorf("FromSlogLevel(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package logger
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	applicationpackage "github.com/Arthur-Conti/guh/libs/log/application_package"
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+	redacthandler "github.com/Arthur-Conti/guh/libs/redact_handler"
+)
+
+const (
+	LevelEnv    = "GUH_LOG_LEVEL"
+	FormatEnv   = "GUH_LOG_FORMAT"
+	TemplateEnv = "GUH_LOG_TEMPLATE"
+	PackagesEnv = "GUH_LOG_PACKAGES"
+)
+
+// Config describes a logger declaratively; it is the "logger" section of .guh.yaml.
+type Config struct {
+	// Level is a level or level spec ("info,db=debug"), defaults to info.
+	Level string `yaml:"level,omitempty"`
+	// Packages adds per-package levels on top of Level.
+	Packages map[string]string `yaml:"packages,omitempty"`
+	// Format builds a single stdout output ("plain" or "json") when Outputs is empty.
+	Format      string            `yaml:"format,omitempty"`
+	Outputs     []OutputConfig    `yaml:"outputs,omitempty"`
+	Sampling    *SamplingConfig   `yaml:"sampling,omitempty"`
+	Deduplicate bool              `yaml:"deduplicate,omitempty"`
+	Redact      *RedactConfig     `yaml:"redact,omitempty"`
+	PackageTag  *PackageTagConfig `yaml:"packageTag,omitempty"`
+}
+
+type OutputConfig struct {
+	// Type is one of plain, json, syslog, http, line, otlp.
+	Type string `yaml:"type"`
+	// File writes plain/json outputs to a file instead of stdout.
+	File       string `yaml:"file,omitempty"`
+	Template   string `yaml:"template,omitempty"`
+	TimeFormat string `yaml:"timeFormat,omitempty"`
+	// Color is auto, always or never.
+	Color string `yaml:"color,omitempty"`
+	// Network and Address are used by syslog and line outputs.
+	Network string `yaml:"network,omitempty"`
+	Address string `yaml:"address,omitempty"`
+	// LineFormat is json or text for line outputs.
+	LineFormat string `yaml:"lineFormat,omitempty"`
+	// AppName is the syslog app name and the OTLP service.name.
+	AppName       string            `yaml:"appName,omitempty"`
+	URL           string            `yaml:"url,omitempty"`
+	Headers       map[string]string `yaml:"headers,omitempty"`
+	BatchSize     int               `yaml:"batchSize,omitempty"`
+	MaxBuffer     int               `yaml:"maxBuffer,omitempty"`
+	FlushInterval time.Duration     `yaml:"flushInterval,omitempty"`
+}
+
+type SamplingConfig struct {
+	Interval   time.Duration `yaml:"interval,omitempty"`
+	First      int           `yaml:"first,omitempty"`
+	Thereafter int           `yaml:"thereafter,omitempty"`
+}
+
+type PackageTagConfig struct {
+	// Format is parentheses, brackets or plain.
+	Format     string `yaml:"format,omitempty"`
+	Width      int    `yaml:"width,omitempty"`
+	Color      bool   `yaml:"color,omitempty"`
+	KeepCase   bool   `yaml:"keepCase,omitempty"`
+	AutoDetect bool   `yaml:"autoDetect,omitempty"`
+}
+
+type RedactConfig struct {
+	Disable bool     `yaml:"disable,omitempty"`
+	Keys    []string `yaml:"keys,omitempty"`
+	Values  []string `yaml:"values,omitempty"`
+	Mask    string   `yaml:"mask,omitempty"`
+}
+
+// ConfigFromEnv overrides cfg with GUH_LOG_LEVEL, GUH_LOG_PACKAGES ("db=debug,cli=info"),
+// GUH_LOG_FORMAT and GUH_LOG_TEMPLATE when they are set. The format and template apply to
+// the plain/json stdout outputs, or to the default output when none is configured.
+func ConfigFromEnv(cfg Config) Config {
+	if level := os.Getenv(LevelEnv); level != "" {
+		cfg.Level = level
+	}
+	if packages := os.Getenv(PackagesEnv); packages != "" {
+		merged := make(map[string]string, len(cfg.Packages))
+		for pkg, level := range cfg.Packages {
+			merged[pkg] = level
+		}
+		for _, part := range strings.Split(packages, ",") {
+			if pkg, level, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+				merged[pkg] = level
+			}
+		}
+		cfg.Packages = merged
+	}
+	format := os.Getenv(FormatEnv)
+	template := os.Getenv(TemplateEnv)
+	if format == "" && template == "" {
+		return cfg
+	}
+	if len(cfg.Outputs) == 0 {
+		if format != "" {
+			cfg.Format = format
+		}
+		if template != "" {
+			cfg.Outputs = []OutputConfig{{Type: cfg.Format, Template: template}}
+		}
+		return cfg
+	}
+	// Only the stdout outputs change, files and network outputs keep their configuration.
+	configured := make([]OutputConfig, len(cfg.Outputs))
+	for i, output := range cfg.Outputs {
+		if isStdoutOutput(output) {
+			if format != "" {
+				output.Type = format
+			}
+			if template != "" {
+				output.Template = template
+			}
+		}
+		configured[i] = output
+	}
+	cfg.Outputs = configured
+	return cfg
+}
+
+func isStdoutOutput(cfg OutputConfig) bool {
+	switch strings.ToLower(cfg.Type) {
+	case "plain", "text", "", "json":
+		return cfg.File == ""
+	}
+	return false
+}
+
+func NewFromConfig(cfg Config) (*Logger, error) {
+	spec := cfg.Level
+	if spec == "" {
+		spec = "info"
+	}
+	for pkg, level := range cfg.Packages {
+		spec += "," + pkg + "=" + level
+	}
+	if _, _, err := loglevels.ParseLevelSpec(spec, loglevels.InfoLevel); err != nil {
+		return nil, errorhandler.Wrap(errorhandler.KindInvalidArgument, "invalid log level", err, errorhandler.WithOp("logger.NewFromConfig"), errorhandler.WithFields(map[string]any{"level": spec}))
+	}
+
+	configs := cfg.Outputs
+	if len(configs) == 0 {
+		format := cfg.Format
+		if format == "" {
+			format = "plain"
+		}
+		configs = []OutputConfig{{Type: format}}
+	}
+	built := make([]outputs.OutputInterface, 0, len(configs))
+	for _, outputCfg := range configs {
+		output, err := buildOutput(outputCfg)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, output)
+	}
+
+	opts := LoggerOpts{
+		LevelStr:           spec,
+		ApplicationPackage: *applicationpackage.NewPackageLevel(),
+		Deduplicate:        cfg.Deduplicate,
+	}
+	if len(built) == 1 {
+		opts.OutputType = built[0]
+	} else {
+		opts.OutputType = outputs.NewMultiOutput(built...)
+	}
+	if cfg.PackageTag != nil {
+		packageLevel, err := buildPackageLevel(*cfg.PackageTag)
+		if err != nil {
+			return nil, err
+		}
+		opts.ApplicationPackage = *packageLevel
+	}
+	if cfg.Sampling != nil {
+		opts.Sampling = &SamplingOpts{Interval: cfg.Sampling.Interval, First: cfg.Sampling.First, Thereafter: cfg.Sampling.Thereafter}
+	}
+	if cfg.Redact != nil {
+		if cfg.Redact.Disable {
+			opts.DisableRedaction = true
+		} else {
+			redactor, err := buildRedactor(*cfg.Redact)
+			if err != nil {
+				return nil, err
+			}
+			opts.Redactor = redactor
+		}
+	}
+
+	return NewLogger(opts), nil
+}
+
+func buildOutput(cfg OutputConfig) (outputs.OutputInterface, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "plain", "text", "":
+		color, err := parseColor(cfg.Color)
+		if err != nil {
+			return nil, err
+		}
+		opts := outputs.PlainOutputOpts{Template: cfg.Template, TimeFormat: cfg.TimeFormat, Color: color}
+		if cfg.File != "" {
+			file, err := openLogFile(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			opts.Writer = file
+		}
+		return outputs.NewPlainOutput(opts), nil
+	case "json":
+		if cfg.File != "" {
+			file, err := openLogFile(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			return outputs.NewJsonWriterOutput(file), nil
+		}
+		return outputs.NewJsonWriterOutput(os.Stdout), nil
+	case "syslog":
+		return outputs.NewSyslogOutput(outputs.SyslogOutputOpts{Network: cfg.Network, Address: cfg.Address, AppName: cfg.AppName}), nil
+	case "line", "tcp", "udp":
+		network := cfg.Network
+		if network == "" && cfg.Type != "line" {
+			network = cfg.Type
+		}
+		format := outputs.LineFormatJSON
+		if strings.EqualFold(cfg.LineFormat, "text") {
+			format = outputs.LineFormatText
+		}
+		return outputs.NewLineOutput(outputs.LineOutputOpts{Network: network, Address: cfg.Address, Format: format}), nil
+	case "http":
+		if cfg.URL == "" {
+			return nil, errorhandler.New(errorhandler.KindInvalidArgument, "http output requires url", errorhandler.WithOp("logger.buildOutput"))
+		}
+		return outputs.NewHTTPBatchOutput(outputs.HTTPBatchOutputOpts{URL: cfg.URL, Headers: cfg.Headers, BatchSize: cfg.BatchSize, MaxBuffer: cfg.MaxBuffer, FlushInterval: cfg.FlushInterval}), nil
+	case "otlp":
+		resource := outputs.OTLPResource{ServiceName: cfg.AppName}
+		if cfg.URL != "" {
+			return outputs.NewOTLPHTTPOutput(cfg.URL, resource, outputs.HTTPBatchOutputOpts{Headers: cfg.Headers, BatchSize: cfg.BatchSize, MaxBuffer: cfg.MaxBuffer, FlushInterval: cfg.FlushInterval}), nil
+		}
+		opts := outputs.OTLPOutputOpts{Writer: os.Stdout, Resource: resource}
+		if cfg.File != "" {
+			file, err := openLogFile(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			opts.Writer = file
+		}
+		return outputs.NewOTLPOutput(opts), nil
+	}
+	return nil, errorhandler.New(errorhandler.KindInvalidArgument, "unknown output type "+strconv.Quote(cfg.Type), errorhandler.WithOp("logger.buildOutput"))
+}
+
+func buildPackageLevel(cfg PackageTagConfig) (*applicationpackage.PackageLevel, error) {
+	opts := applicationpackage.PackageLevelOpts{Width: cfg.Width, Color: cfg.Color, KeepCase: cfg.KeepCase, AutoDetect: cfg.AutoDetect}
+	switch strings.ToLower(cfg.Format) {
+	case "", "parentheses":
+		opts.Format = applicationpackage.ParenthesesFormat
+	case "brackets":
+		opts.Format = applicationpackage.BracketsFormat
+	case "plain":
+		opts.Format = applicationpackage.PlainFormat
+	default:
+		return nil, errorhandler.New(errorhandler.KindInvalidArgument, "unknown package tag format "+strconv.Quote(cfg.Format), errorhandler.WithOp("logger.buildPackageLevel"))
+	}
+	return applicationpackage.NewPackageLevelWithOpts(opts), nil
+}
+
+func parseColor(color string) (outputs.ColorMode, error) {
+	switch strings.ToLower(color) {
+	case "", "auto":
+		return outputs.ColorAuto, nil
+	case "always", "true":
+		return outputs.ColorAlways, nil
+	case "never", "false":
+		return outputs.ColorNever, nil
+	}
+	return outputs.ColorAuto, errorhandler.New(errorhandler.KindInvalidArgument, "unknown color mode "+strconv.Quote(color), errorhandler.WithOp("logger.parseColor"))
+}
+
+func openLogFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errorhandler.Wrap(errorhandler.KindInternal, "Error opening log file", err, errorhandler.WithOp("logger.openLogFile"), errorhandler.WithFields(map[string]any{"file": path}))
+	}
+	return file, nil
+}
+
+func buildRedactor(cfg RedactConfig) (*redacthandler.Redactor, error) {
+	values := make([]*regexp.Regexp, 0, len(cfg.Values))
+	for _, pattern := range cfg.Values {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errorhandler.Wrap(errorhandler.KindInvalidArgument, "invalid redact pattern", err, errorhandler.WithOp("logger.buildRedactor"), errorhandler.WithFields(map[string]any{"pattern": pattern}))
+		}
+		values = append(values, re)
+	}
+	return redacthandler.WithDefaults(redacthandler.RedactorOpts{KeyPatterns: cfg.Keys, ValuePatterns: values, Mask: cfg.Mask}), nil
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigFromEnvOutputs(t *testing.T) {
+	configured := []OutputConfig{
+		{Type: "plain", Template: "{{.Message}}"},
+		{Type: "json", File: "app.log"},
+		{Type: "syslog", Address: "localhost:514"},
+	}
+	tests := []struct {
+		name     string
+		format   string
+		template string
+		cfg      Config
+		want     Config
+	}{
+		{
+			name: "nothing set",
+			cfg:  Config{Outputs: configured},
+			want: Config{Outputs: configured},
+		},
+		{
+			name:   "format without outputs",
+			format: "json",
+			want:   Config{Format: "json"},
+		},
+		{
+			name:     "template without outputs",
+			template: "{{.Level}} {{.Message}}",
+			cfg:      Config{Format: "plain"},
+			want:     Config{Format: "plain", Outputs: []OutputConfig{{Type: "plain", Template: "{{.Level}} {{.Message}}"}}},
+		},
+		{
+			name:   "format keeps file and network outputs",
+			format: "json",
+			cfg:    Config{Outputs: configured},
+			want: Config{Outputs: []OutputConfig{
+				{Type: "json", Template: "{{.Message}}"},
+				{Type: "json", File: "app.log"},
+				{Type: "syslog", Address: "localhost:514"},
+			}},
+		},
+		{
+			name:     "template keeps file and network outputs",
+			template: "{{.Level}} {{.Message}}",
+			cfg:      Config{Outputs: configured},
+			want: Config{Outputs: []OutputConfig{
+				{Type: "plain", Template: "{{.Level}} {{.Message}}"},
+				{Type: "json", File: "app.log"},
+				{Type: "syslog", Address: "localhost:514"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FormatEnv, tt.format)
+			t.Setenv(TemplateEnv, tt.template)
+			if got := ConfigFromEnv(tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConfigFromEnv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if configured[0].Template != "{{.Message}}" {
+		t.Errorf("ConfigFromEnv modified the configured outputs: %+v", configured)
+	}
+}
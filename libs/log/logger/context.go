@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync/atomic"
+
+	applicationpackage "github.com/Arthur-Conti/guh/libs/log/application_package"
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDField  = "request_id"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+	traceKey
+)
+
+var defaultLogger atomic.Pointer[Logger]
+
+// Default returns the logger used when a context carries none. Until SetDefault is called
+// it is a plain stdout logger at info level.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	l := NewLogger(LoggerOpts{
+		OutputType:         outputs.NewPlainOutput(outputs.PlainOutputOpts{}),
+		Level:              loglevels.InfoLevel,
+		ApplicationPackage: *applicationpackage.NewPackageLevel(),
+	})
+	if defaultLogger.CompareAndSwap(nil, l) {
+		return l
+	}
+	return defaultLogger.Load()
+}
+
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in ctx (or Default) with the context's request ID
+// and trace context attached.
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(loggerKey).(*Logger)
+	if !ok || l == nil {
+		l = Default()
+	}
+	if fields := contextFields(ctx); len(fields) > 0 {
+		l = l.With(fields)
+	}
+	if sc, ok := SpanContextFromContext(ctx); ok {
+		child := *l
+		child.span = sc
+		l = &child
+	}
+	return l
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+func contextFields(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+	fields := map[string]any{}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		fields[RequestIDField] = id
+	}
+	return fields
+}
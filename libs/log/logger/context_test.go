@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+func TestFromContext(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() { SetDefault(previous) })
+
+	defaultOut := outputs.NewMemoryOutput()
+	SetDefault(NewLogger(LoggerOpts{OutputType: defaultOut, Level: loglevels.InfoLevel}))
+	out := outputs.NewMemoryOutput()
+	stored := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel}).With(map[string]any{"service": "orders"})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		out       *outputs.MemoryOutput
+		requestID any
+	}{
+		{"empty context uses Default", context.Background(), defaultOut, nil},
+		{"request ID on Default", WithRequestID(context.Background(), "req-1"), defaultOut, "req-1"},
+		{"stored logger", WithContext(context.Background(), stored), out, nil},
+		{"stored logger with request ID", WithRequestID(WithContext(context.Background(), stored), "req-2"), out, "req-2"},
+		{"empty request ID ignored", WithRequestID(WithContext(context.Background(), stored), ""), out, nil},
+		{"nil logger uses Default", WithContext(context.Background(), nil), defaultOut, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultOut.Reset()
+			out.Reset()
+			FromContext(tt.ctx).Info(LogMessage{Message: "handled"})
+			entries := tt.out.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries on the expected output, want 1", len(entries))
+			}
+			if got, ok := entries[0].Fields[RequestIDField]; got != tt.requestID || ok != (tt.requestID != nil) {
+				t.Errorf("request_id = %v, want %v", got, tt.requestID)
+			}
+			if tt.out == out && entries[0].Fields["service"] != "orders" {
+				t.Errorf("fields of the stored logger were lost: %v", entries[0].Fields)
+			}
+		})
+	}
+
+	out.Reset()
+	FromContext(WithRequestID(WithContext(context.Background(), stored), "req-3")).Info(LogMessage{Message: "child"})
+	stored.Info(LogMessage{Message: "parent"})
+	if _, ok := out.Entries()[1].Fields[RequestIDField]; ok {
+		t.Error("FromContext added the request ID to the stored logger")
+	}
+}
+
+func TestMessageContext(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel})
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	l.Info(LogMessage{Message: "with context", Context: ctx, Fields: map[string]any{"user": 7}})
+	l.Info(LogMessage{Message: "explicit field wins", Context: ctx, Fields: map[string]any{RequestIDField: "override"}})
+	l.Info(LogMessage{Message: "without context"})
+
+	entries := out.Entries()
+	if entries[0].Fields[RequestIDField] != "req-1" || entries[0].Fields["user"] != 7 {
+		t.Errorf("fields = %v, want request_id and user", entries[0].Fields)
+	}
+	if entries[1].Fields[RequestIDField] != "override" {
+		t.Errorf("fields = %v, want the message field to win", entries[1].Fields)
+	}
+	if len(entries[2].Fields) != 0 {
+		t.Errorf("fields = %v, want none", entries[2].Fields)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if id, ok := RequestIDFromContext(context.Background()); ok || id != "" {
+		t.Errorf("RequestIDFromContext(empty) = %q, %v", id, ok)
+	}
+	if id, ok := RequestIDFromContext(WithRequestID(context.Background(), "abc")); !ok || id != "abc" {
+		t.Errorf("RequestIDFromContext() = %q, %v, want abc", id, ok)
+	}
+
+	format := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := NewRequestID()
+		if !format.MatchString(id) || seen[id] {
+			t.Fatalf("NewRequestID() = %q, want unique 32 hex characters", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSetDefaultNil(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() { SetDefault(previous) })
+	SetDefault(nil)
+	if Default() == nil {
+		t.Fatal("Default() = nil after SetDefault(nil)")
+	}
+}
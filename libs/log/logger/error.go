@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+// Err logs err at the level matching its errorhandler.Kind, with the wrapped chain
+// (op, kind, message, fields) and the captured stack trace as fields.
+func (l *Logger) Err(err error) {
+	l.logErr(LogMessage{}, err)
+}
+
+// ErrWith is Err with an explicit package, message and extra fields.
+func (l *Logger) ErrWith(message LogMessage, err error) {
+	l.logErr(message, err)
+}
+
+// LevelForKind maps client errors to warning and server side failures to error.
+func LevelForKind(kind errorhandler.Kind) loglevels.LogLevel {
+	switch kind {
+	case errorhandler.KindInvalidArgument,
+		errorhandler.KindUnauthenticated,
+		errorhandler.KindPermissionDenied,
+		errorhandler.KindNotFound,
+		errorhandler.KindAlreadyExists,
+		errorhandler.KindResourceExhausted,
+		errorhandler.KindFailedPrecondition,
+		errorhandler.KindAborted,
+		errorhandler.KindOutOfRange:
+		return loglevels.WarningLevel
+	default:
+		return loglevels.ErrorLevel
+	}
+}
+
+func (l *Logger) logErr(message LogMessage, err error) {
+	if err == nil {
+		return
+	}
+	level := loglevels.ErrorLevel
+	var top *errorhandler.Error
+	if errors.As(err, &top) {
+		level = LevelForKind(top.Kind)
+	}
+
+	if message.Message == "" {
+		message.Message = err.Error()
+		if top != nil {
+			message.Message = top.Message
+		}
+	} else if len(message.Vals) > 0 {
+		message.Message = fmt.Sprintf(message.Message, message.Vals...)
+	}
+	message.Vals = nil
+
+	fields := make(map[string]any, len(message.Fields)+4)
+	for key, value := range message.Fields {
+		fields[key] = value
+	}
+	for key, value := range ErrorFields(err) {
+		fields[key] = value
+	}
+	message.Fields = fields
+
+	// Skip logErr; log already skips Err/ErrWith as the exported method.
+	l.log(1, level, message, false)
+}
+
+// ErrorFields describes err as structured log fields: "error", "error.kind", "error.chain"
+// with one entry per wrapped error and "error.stack" from the innermost captured stack.
+func ErrorFields(err error) map[string]any {
+	fields := map[string]any{"error": err.Error()}
+	var chain []map[string]any
+	var stack []runtime.Frame
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		e, ok := current.(*errorhandler.Error)
+		if !ok {
+			if _, wrapped := current.(interface{ Unwrap() error }); wrapped {
+				continue
+			}
+			chain = append(chain, map[string]any{"message": current.Error()})
+			break
+		}
+		link := map[string]any{"kind": e.Kind.String(), "message": e.Message}
+		if e.Op != "" {
+			link["op"] = e.Op
+		}
+		if len(e.Fields) > 0 {
+			link["fields"] = e.Fields
+		}
+		chain = append(chain, link)
+		if len(chain) == 1 {
+			fields["error.kind"] = e.Kind.String()
+		}
+		if frames := e.StackTrace(); len(frames) > 0 {
+			stack = frames
+		}
+	}
+	if len(chain) > 0 {
+		fields["error.chain"] = chain
+	}
+	if len(stack) > 0 {
+		fields["error.stack"] = formatStack(stack)
+	}
+	return fields
+}
+
+func formatStack(frames []runtime.Frame) []string {
+	lines := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		lines = append(lines, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+	}
+	return lines
+}
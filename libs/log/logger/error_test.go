@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+func TestErrReportsCaller(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out})
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Err(errorhandler.New(errorhandler.KindNotFound, "user missing"))
+	l.ErrWith(LogMessage{Message: "lookup failed"}, errorhandler.New(errorhandler.KindInternal, "db down"))
+
+	entries := out.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, entry := range entries {
+		frame, ok := entry.Caller()
+		if !ok {
+			t.Fatalf("entry %d has no caller", i)
+		}
+		if filepath.Base(frame.File) != filepath.Base(file) || frame.Line != line+1+i {
+			t.Errorf("entry %d caller = %s:%d, want %s:%d", i, filepath.Base(frame.File), frame.Line, filepath.Base(file), line+1+i)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"testing"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+	"github.com/Arthur-Conti/guh/libs/log/outputs/outputstest"
+)
+
+func TestEnabled(t *testing.T) {
+	l := NewLogger(LoggerOpts{OutputType: outputs.NewMemoryOutput(), LevelStr: "warning,db=debug,db.migrate=error,CLI=trace"})
+	tests := []struct {
+		pkg   string
+		level loglevels.LogLevel
+		want  bool
+	}{
+		{"", loglevels.InfoLevel, false},
+		{"", loglevels.WarningLevel, true},
+		{"api", loglevels.InfoLevel, false},
+		{"db", loglevels.DebugLevel, true},
+		{"db", loglevels.TraceLevel, false},
+		{"db.query", loglevels.DebugLevel, true},
+		{"db.migrate", loglevels.WarningLevel, false},
+		{"db.migrate.step", loglevels.ErrorLevel, true},
+		{"Db.Migrate", loglevels.WarningLevel, false},
+		{"cli", loglevels.TraceLevel, true},
+		{"dbx", loglevels.DebugLevel, false},
+	}
+	for _, tt := range tests {
+		if got := l.Enabled(tt.pkg, tt.level); got != tt.want {
+			t.Errorf("Enabled(%q, %v) = %v, want %v", tt.pkg, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLevelChanges(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel})
+	child := l.With(map[string]any{"request_id": "r1"})
+
+	child.Debug(LogMessage{Message: "hidden"})
+	l.SetLevel(loglevels.DebugLevel)
+	child.Debug(LogMessage{Message: "shown after SetLevel"})
+	l.SetPackageLevel("DB", loglevels.ErrorLevel)
+	child.Warning(LogMessage{ApplicationPackage: "db.pool", Message: "hidden by override"})
+
+	if err := l.SetLevelSpec("info,db=loud"); err == nil {
+		t.Fatal("SetLevelSpec() with an invalid spec succeeded")
+	}
+	if l.Level() != loglevels.DebugLevel || l.Enabled("db", loglevels.WarningLevel) {
+		t.Error("an invalid spec changed the levels")
+	}
+	if err := l.SetLevelSpec("error,api=trace"); err != nil {
+		t.Fatal(err)
+	}
+	child.Trace(LogMessage{ApplicationPackage: "api", Message: "api trace"})
+	child.Warning(LogMessage{ApplicationPackage: "db", Message: "db warning after spec"})
+
+	outputstest.AssertNotLogged(t, out, loglevels.DebugLevel, "hidden")
+	outputstest.AssertLogged(t, out, loglevels.DebugLevel, "shown after SetLevel")
+	outputstest.AssertNotLogged(t, out, loglevels.WarningLevel, "hidden by override")
+	outputstest.AssertLogged(t, out, loglevels.TraceLevel, "api trace")
+	outputstest.AssertNotLogged(t, out, loglevels.WarningLevel, "db warning after spec")
+}
+
+func TestInvalidLevelStrKeepsLevel(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.WarningLevel, LevelStr: "chatty"})
+	if l.Level() != loglevels.WarningLevel {
+		t.Errorf("Level() = %v, want the configured warning level", l.Level())
+	}
+	outputstest.AssertLogged(t, out, loglevels.WarningLevel, `invalid level "chatty"`)
+}
@@ -1,7 +1,12 @@
 package logger
 
+import "context"
+
 type LogMessage struct {
 	ApplicationPackage string
 	Message            string
 	Vals               []any
+	Fields             map[string]any
+	// Context, when set, adds correlation data such as the request ID to the entry.
+	Context context.Context
 }
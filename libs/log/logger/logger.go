@@ -1,115 +1,311 @@
 package logger
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
 	applicationpackage "github.com/Arthur-Conti/guh/libs/log/application_package"
 	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
 	"github.com/Arthur-Conti/guh/libs/log/outputs"
+	redacthandler "github.com/Arthur-Conti/guh/libs/redact_handler"
 )
 
-var levelParse = map[string]int{
-	"debug":   1,
-	"warning": 2,
-	"info":    3,
-	"error":   4,
-}
-
 type Logger struct {
-	opts LoggerOpts
+	opts      LoggerOpts
+	level     *atomic.Int32
+	overrides *atomic.Pointer[map[string]loglevels.LogLevel]
+	fields    map[string]any
+	span      SpanContext
+	sampler   *sampler
+	dedup     *deduper
 }
 
 type LoggerOpts struct {
 	OutputType          outputs.OutputInterface
 	SecondaryOutputType outputs.OutputInterface
-	Level               int
-	LevelStr            string
-	ApplicationPackage  applicationpackage.PackageLevel
+	Level               loglevels.LogLevel
+	// LevelStr accepts a single level ("info") or a spec with package overrides ("info,db=debug,cli=warning").
+	LevelStr           string
+	ApplicationPackage applicationpackage.PackageLevel
+	// Sampling, when set, limits repeated entries per level and message template.
+	Sampling *SamplingOpts
+	// Deduplicate collapses identical consecutive entries into "last message repeated N times".
+	Deduplicate bool
+	// Redactor masks secrets in messages and fields, defaults to redacthandler.Default().
+	Redactor         *redacthandler.Redactor
+	DisableRedaction bool
 }
 
 func NewLogger(opts LoggerOpts) *Logger {
-	opts.Level = levelParse[opts.LevelStr]
-	return &Logger{
-		opts: opts,
+	l := &Logger{
+		opts:      opts,
+		level:     &atomic.Int32{},
+		overrides: &atomic.Pointer[map[string]loglevels.LogLevel]{},
+	}
+	if opts.Redactor == nil && !opts.DisableRedaction {
+		l.opts.Redactor = redacthandler.Default()
+	}
+	if opts.Sampling != nil {
+		l.sampler = newSampler(*opts.Sampling)
 	}
+	if opts.Deduplicate {
+		l.dedup = &deduper{}
+	}
+	l.level.Store(int32(opts.Level))
+	l.overrides.Store(&map[string]loglevels.LogLevel{})
+	if opts.LevelStr != "" {
+		if err := l.SetLevelSpec(opts.LevelStr); err != nil {
+			l.Warningf(LogMessage{ApplicationPackage: "logger", Message: "invalid level %q, keeping %v: %v", Vals: []any{opts.LevelStr, l.Level(), err}})
+		}
+	}
+	return l
 }
 
-func (l *Logger) Debug(message LogMessage) {
-	if l.opts.Level > 1 {
-		return
+// With returns a child logger that adds fields to every entry. Levels stay shared with the parent.
+func (l *Logger) With(fields map[string]any) *Logger {
+	child := *l
+	child.fields = l.mergeFields(fields)
+	return &child
+}
+
+// Stats returns how many entries were dropped by sampling and deduplication.
+func (l *Logger) Stats() SamplingStats {
+	var stats SamplingStats
+	if l.sampler != nil {
+		stats.Sampled = l.sampler.suppressed.Load()
 	}
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Log(packageName, loglevels.DebugLevel, message.Message)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Log(packageName, loglevels.DebugLevel, message.Message)
+	if l.dedup != nil {
+		stats.Deduplicated = l.dedup.suppressed.Load()
 	}
+	return stats
 }
 
-func (l *Logger) Debugf(message LogMessage) {
-	if l.opts.Level > 1 {
+// Flush writes the pending "repeated N times" entry when deduplication is enabled.
+func (l *Logger) Flush() {
+	if l.dedup == nil {
 		return
 	}
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Logf(packageName, loglevels.DebugLevel, message.Message, message.Vals...)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Logf(packageName, loglevels.DebugLevel, message.Message, message.Vals...)
+	if summary := l.dedup.flush(); summary != nil {
+		l.write(*summary)
 	}
 }
 
-func (l *Logger) Warning(message LogMessage) {
-	if l.opts.Level > 2 {
-		return
+// Close flushes pending entries and closes outputs that hold connections or buffers.
+func (l *Logger) Close() error {
+	l.Flush()
+	var errs []error
+	for _, output := range []outputs.OutputInterface{l.opts.OutputType, l.opts.SecondaryOutputType} {
+		if closer, ok := output.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
 	}
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Log(packageName, loglevels.WarningLevel, message.Message)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Log(packageName, loglevels.WarningLevel, message.Message)
+	return errors.Join(errs...)
+}
+
+func (l *Logger) Level() loglevels.LogLevel {
+	return loglevels.LogLevel(l.level.Load())
+}
+
+func (l *Logger) SetLevel(level loglevels.LogLevel) {
+	l.level.Store(int32(level))
+}
+
+func (l *Logger) SetPackageLevel(applicationPackage string, level loglevels.LogLevel) {
+	for {
+		current := l.overrides.Load()
+		next := make(map[string]loglevels.LogLevel, len(*current)+1)
+		for pkg, lvl := range *current {
+			next[pkg] = lvl
+		}
+		next[strings.ToLower(applicationPackage)] = level
+		if l.overrides.CompareAndSwap(current, &next) {
+			return
+		}
 	}
 }
 
-func (l *Logger) Warningf(message LogMessage) {
-	if l.opts.Level > 2 {
-		return
+// SetLevelSpec replaces the default level and every package override with the ones in spec.
+func (l *Logger) SetLevelSpec(spec string) error {
+	level, overrides, err := loglevels.ParseLevelSpec(spec, l.Level())
+	if err != nil {
+		return err
 	}
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Logf(packageName, loglevels.WarningLevel, message.Message, message.Vals...)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Logf(packageName, loglevels.WarningLevel, message.Message, message.Vals...)
+	l.overrides.Store(&overrides)
+	l.SetLevel(level)
+	return nil
+}
+
+// Enabled checks the most specific package override first, so "db.migrate" falls back to "db".
+func (l *Logger) Enabled(applicationPackage string, level loglevels.LogLevel) bool {
+	overrides := *l.overrides.Load()
+	if len(overrides) > 0 && applicationPackage != "" {
+		for _, pkg := range applicationpackage.Parents(strings.ToLower(applicationPackage)) {
+			if override, ok := overrides[pkg]; ok {
+				return level >= override
+			}
+		}
 	}
+	return level >= l.Level()
+}
+
+func (l *Logger) Trace(message LogMessage) {
+	l.log(0, loglevels.TraceLevel, message, false)
+}
+
+func (l *Logger) Tracef(message LogMessage) {
+	l.log(0, loglevels.TraceLevel, message, true)
+}
+
+func (l *Logger) Debug(message LogMessage) {
+	l.log(0, loglevels.DebugLevel, message, false)
+}
+
+func (l *Logger) Debugf(message LogMessage) {
+	l.log(0, loglevels.DebugLevel, message, true)
+}
+
+func (l *Logger) Warning(message LogMessage) {
+	l.log(0, loglevels.WarningLevel, message, false)
+}
+
+func (l *Logger) Warningf(message LogMessage) {
+	l.log(0, loglevels.WarningLevel, message, true)
 }
 
 func (l *Logger) Info(message LogMessage) {
-	if l.opts.Level > 3 {
+	l.log(0, loglevels.InfoLevel, message, false)
+}
+
+func (l *Logger) Infof(message LogMessage) {
+	l.log(0, loglevels.InfoLevel, message, true)
+}
+
+func (l *Logger) Error(message LogMessage) {
+	l.log(0, loglevels.ErrorLevel, message, false)
+}
+
+func (l *Logger) Errorf(message LogMessage) {
+	l.log(0, loglevels.ErrorLevel, message, true)
+}
+
+// Fatal logs message, closes the outputs so queued entries are sent and exits with status 1.
+func (l *Logger) Fatal(message LogMessage) {
+	l.log(0, loglevels.FatalLevel, message, false)
+	l.Close()
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalf(message LogMessage) {
+	l.log(0, loglevels.FatalLevel, message, true)
+	l.Close()
+	os.Exit(1)
+}
+
+// LogAt logs message at level, formatting it when Vals are set. skip is the number of extra
+// stack frames between the reported caller and LogAt, for helpers that wrap the logger.
+func (l *Logger) LogAt(skip int, level loglevels.LogLevel, message LogMessage) {
+	l.log(skip, level, message, len(message.Vals) > 0)
+}
+
+func (l *Logger) log(skip int, level loglevels.LogLevel, message LogMessage, format bool) {
+	// Skip runtime.Callers, log and the exported level method.
+	var pcs [1]uintptr
+	runtime.Callers(3+skip, pcs[:])
+	if message.ApplicationPackage == "" && l.opts.ApplicationPackage.AutoDetect() {
+		message.ApplicationPackage = applicationpackage.FromPC(pcs[0])
+	}
+	if !l.Enabled(message.ApplicationPackage, level) {
 		return
 	}
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Log(packageName, loglevels.InfoLevel, message.Message)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Log(packageName, loglevels.InfoLevel, message.Message)
+	text := message.Message
+	if format {
+		text = fmt.Sprintf(text, message.Vals...)
 	}
+	l.emit(l.traced(message.Context, outputs.Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Package: message.ApplicationPackage,
+		Message: text,
+		Fields:  l.messageFields(message),
+		PC:      pcs[0],
+	}), message.Message)
 }
 
-func (l *Logger) Infof(message LogMessage) {
-	if l.opts.Level > 3 {
+// emit applies sampling and deduplication, template being the unformatted message.
+func (l *Logger) emit(entry outputs.Entry, template string) {
+	if l.opts.Redactor != nil && !l.opts.DisableRedaction {
+		entry.Message = l.opts.Redactor.String(entry.Message)
+		entry.Fields = l.opts.Redactor.Fields(entry.Fields)
+	}
+	if l.sampler != nil && !l.sampler.allow(entry, template) {
 		return
 	}
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Logf(packageName, loglevels.InfoLevel, message.Message, message.Vals...)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Logf(packageName, loglevels.InfoLevel, message.Message, message.Vals...)
+	if l.dedup != nil {
+		summary, ok := l.dedup.check(entry)
+		if summary != nil {
+			l.write(*summary)
+		}
+		if !ok {
+			return
+		}
 	}
+	l.write(entry)
 }
 
-func (l *Logger) Error(message LogMessage) {
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Log(packageName, loglevels.ErrorLevel, message.Message)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Log(packageName, loglevels.ErrorLevel, message.Message)
+func (l *Logger) write(entry outputs.Entry) {
+	entry.PackageTag = l.opts.ApplicationPackage.Style(entry.Package)
+	entry.PackageColor = l.opts.ApplicationPackage.Color(entry.Package)
+	for _, output := range []outputs.OutputInterface{l.opts.OutputType, l.opts.SecondaryOutputType} {
+		if output == nil {
+			continue
+		}
+		if entryOutput, ok := output.(outputs.EntryOutput); ok {
+			entryOutput.LogEntry(entry)
+		} else {
+			output.Log(entry.PackageTag, entry.Level, entry.Message)
+		}
 	}
 }
 
-func (l *Logger) Errorf(message LogMessage) {
-	packageName := l.opts.ApplicationPackage.Style(message.ApplicationPackage)
-	l.opts.OutputType.Logf(packageName, loglevels.ErrorLevel, message.Message, message.Vals...)
-	if l.opts.SecondaryOutputType != nil {
-		l.opts.SecondaryOutputType.Logf(packageName, loglevels.ErrorLevel, message.Message, message.Vals...)
+// traced sets the entry's trace and span IDs from ctx, or from the logger built by FromContext.
+func (l *Logger) traced(ctx context.Context, entry outputs.Entry) outputs.Entry {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		sc = l.span
+	}
+	entry.TraceID = sc.TraceID
+	entry.SpanID = sc.SpanID
+	return entry
+}
+
+func (l *Logger) messageFields(message LogMessage) map[string]any {
+	fields := contextFields(message.Context)
+	if len(fields) == 0 {
+		return l.mergeFields(message.Fields)
+	}
+	for key, value := range message.Fields {
+		fields[key] = value
+	}
+	return l.mergeFields(fields)
+}
+
+func (l *Logger) mergeFields(fields map[string]any) map[string]any {
+	if len(l.fields) == 0 {
+		return fields
+	}
+	merged := make(map[string]any, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
 	}
+	return merged
 }
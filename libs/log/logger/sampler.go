@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+// SamplingOpts logs the First entries of each level+message template per Interval,
+// then every Thereafter-th one. Thereafter 0 drops the rest of the interval.
+type SamplingOpts struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+type SamplingStats struct {
+	Sampled      uint64
+	Deduplicated uint64
+}
+
+// maxSamplerKeys bounds the tracked templates. Once it is reached and no window has
+// expired, entries with new templates are logged without being sampled.
+const maxSamplerKeys = 4096
+
+type samplerKey struct {
+	level    loglevels.LogLevel
+	pkg      string
+	template string
+}
+
+type samplerCounter struct {
+	windowStart time.Time
+	count       int
+}
+
+type sampler struct {
+	opts       SamplingOpts
+	mu         sync.Mutex
+	counters   map[samplerKey]*samplerCounter
+	suppressed atomic.Uint64
+}
+
+func newSampler(opts SamplingOpts) *sampler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.First <= 0 {
+		opts.First = 1
+	}
+	return &sampler{opts: opts, counters: map[samplerKey]*samplerCounter{}}
+}
+
+func (s *sampler) allow(entry outputs.Entry, template string) bool {
+	key := samplerKey{level: entry.Level, pkg: entry.Package, template: template}
+	now := entry.Time
+
+	s.mu.Lock()
+	counter, ok := s.counters[key]
+	if !ok {
+		if len(s.counters) >= maxSamplerKeys {
+			s.prune(now)
+		}
+		if len(s.counters) >= maxSamplerKeys {
+			s.mu.Unlock()
+			return true
+		}
+		counter = &samplerCounter{windowStart: now}
+		s.counters[key] = counter
+	}
+	if now.Sub(counter.windowStart) >= s.opts.Interval {
+		counter.windowStart = now
+		counter.count = 0
+	}
+	counter.count++
+	n := counter.count
+	s.mu.Unlock()
+
+	if n <= s.opts.First {
+		return true
+	}
+	if s.opts.Thereafter > 0 && (n-s.opts.First)%s.opts.Thereafter == 0 {
+		return true
+	}
+	s.suppressed.Add(1)
+	return false
+}
+
+func (s *sampler) prune(now time.Time) {
+	for key, counter := range s.counters {
+		if now.Sub(counter.windowStart) >= s.opts.Interval {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// deduper collapses identical consecutive entries into a single "repeated N times" entry.
+type deduper struct {
+	mu         sync.Mutex
+	lastKey    string
+	last       outputs.Entry
+	repeats    int
+	suppressed atomic.Uint64
+}
+
+// check reports whether entry should be written and, when a run of duplicates just ended,
+// the summary entry to write before it.
+func (d *deduper) check(entry outputs.Entry) (*outputs.Entry, bool) {
+	key := fmt.Sprint(entry.Level, "|", entry.Package, "|", entry.Message, "|", entry.Fields)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if key == d.lastKey {
+		d.repeats++
+		d.suppressed.Add(1)
+		return nil, false
+	}
+	summary := d.summary()
+	d.lastKey = key
+	d.last = entry
+	return summary, true
+}
+
+func (d *deduper) flush() *outputs.Entry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.summary()
+}
+
+func (d *deduper) summary() *outputs.Entry {
+	if d.repeats == 0 {
+		return nil
+	}
+	summary := d.last
+	summary.Time = time.Now()
+	summary.Message = fmt.Sprintf("last message repeated %d times", d.repeats)
+	summary.Fields = map[string]any{"repeated": d.repeats, "message": d.last.Message}
+	d.repeats = 0
+	return &summary
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+	"github.com/Arthur-Conti/guh/libs/log/outputs/outputstest"
+)
+
+func TestSamplerAllow(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name    string
+		opts    SamplingOpts
+		offsets []time.Duration
+		want    []bool
+	}{
+		{"first then drop", SamplingOpts{Interval: time.Second, First: 2}, []time.Duration{0, 0, 0, 0}, []bool{true, true, false, false}},
+		{"thereafter", SamplingOpts{Interval: time.Second, First: 1, Thereafter: 2}, []time.Duration{0, 0, 0, 0, 0}, []bool{true, false, true, false, true}},
+		{"new interval resets", SamplingOpts{Interval: time.Second, First: 1}, []time.Duration{0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond}, []bool{true, false, true, false}},
+		{"defaults to one per second", SamplingOpts{}, []time.Duration{0, 999 * time.Millisecond, time.Second}, []bool{true, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSampler(tt.opts)
+			dropped := 0
+			for i, offset := range tt.offsets {
+				entry := outputs.Entry{Time: start.Add(offset), Level: loglevels.InfoLevel, Package: "api"}
+				got := s.allow(entry, "user %s logged in")
+				if got != tt.want[i] {
+					t.Errorf("entry %d at +%v: allow = %v, want %v", i, offset, got, tt.want[i])
+				}
+				if !got {
+					dropped++
+				}
+			}
+			if s.suppressed.Load() != uint64(dropped) {
+				t.Errorf("suppressed = %d, want %d", s.suppressed.Load(), dropped)
+			}
+		})
+	}
+}
+
+func TestSamplerKeys(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	s := newSampler(SamplingOpts{Interval: time.Second, First: 1})
+	info := outputs.Entry{Time: start, Level: loglevels.InfoLevel, Package: "api"}
+	if !s.allow(info, "a") || s.allow(info, "a") {
+		t.Fatal("template a was not sampled")
+	}
+	warning := info
+	warning.Level = loglevels.WarningLevel
+	other := info
+	other.Package = "db"
+	if !s.allow(warning, "a") || !s.allow(other, "a") || !s.allow(info, "b") {
+		t.Error("level, package and template must be sampled separately")
+	}
+
+	for i := len(s.counters); i < maxSamplerKeys; i++ {
+		s.allow(info, fmt.Sprint("template ", i))
+	}
+	if !s.allow(info, "overflow") || !s.allow(info, "overflow") || len(s.counters) != maxSamplerKeys {
+		t.Errorf("over the key limit: %d keys tracked, want new templates logged untracked", len(s.counters))
+	}
+
+	later := info
+	later.Time = start.Add(time.Second)
+	if !s.allow(later, "fresh") || len(s.counters) != 1 {
+		t.Errorf("%d keys after expired windows, want only the new one", len(s.counters))
+	}
+}
+
+func TestLoggerSampling(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel, Sampling: &SamplingOpts{Interval: time.Hour, First: 2, Thereafter: 3}})
+	for i := 1; i <= 8; i++ {
+		l.Infof(LogMessage{ApplicationPackage: "api", Message: "request %d", Vals: []any{i}})
+	}
+	for _, n := range []int{1, 2, 5, 8} {
+		outputstest.AssertLogged(t, out, loglevels.InfoLevel, fmt.Sprint("request ", n))
+	}
+	for _, n := range []int{3, 4, 6, 7} {
+		outputstest.AssertNotLogged(t, out, loglevels.InfoLevel, fmt.Sprint("request ", n))
+	}
+	if stats := l.Stats(); stats.Sampled != 4 || stats.Deduplicated != 0 {
+		t.Errorf("Stats() = %+v, want 4 sampled", stats)
+	}
+}
+
+func TestLoggerDeduplicate(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel, Deduplicate: true})
+	for i := 0; i < 3; i++ {
+		l.Warning(LogMessage{ApplicationPackage: "db", Message: "connection lost"})
+	}
+	l.Warning(LogMessage{ApplicationPackage: "db", Message: "connection lost", Fields: map[string]any{"host": "a"}})
+	l.Info(LogMessage{ApplicationPackage: "db", Message: "reconnected"})
+
+	var messages []string
+	for _, entry := range out.Entries() {
+		messages = append(messages, entry.Message)
+	}
+	want := []string{"connection lost", "last message repeated 2 times", "connection lost", "reconnected"}
+	if fmt.Sprint(messages) != fmt.Sprint(want) {
+		t.Fatalf("messages = %q, want %q", messages, want)
+	}
+	summary := out.Entries()[1]
+	if summary.Level != loglevels.WarningLevel || summary.Package != "db" || summary.Fields["repeated"] != 2 || summary.Fields["message"] != "connection lost" {
+		t.Errorf("summary = %+v", summary)
+	}
+	if stats := l.Stats(); stats.Deduplicated != 2 {
+		t.Errorf("Stats() = %+v, want 2 deduplicated", stats)
+	}
+}
+
+func TestLoggerDeduplicateFlush(t *testing.T) {
+	for _, name := range []string{"Flush", "Close"} {
+		t.Run(name, func(t *testing.T) {
+			out := outputs.NewMemoryOutput()
+			l := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel, Deduplicate: true})
+			for i := 0; i < 4; i++ {
+				l.Info(LogMessage{Message: "tick"})
+			}
+			outputstest.AssertNotLogged(t, out, loglevels.InfoLevel, "repeated")
+			if name == "Flush" {
+				l.Flush()
+			} else if err := l.Close(); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+			outputstest.AssertLogged(t, out, loglevels.InfoLevel, "last message repeated 3 times")
+
+			l.Flush()
+			if got := len(outputstest.Find(out, loglevels.InfoLevel, "repeated")); got != 1 {
+				t.Errorf("%d summaries after a second Flush, want 1", got)
+			}
+		})
+	}
+}
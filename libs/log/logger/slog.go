@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	applicationpackage "github.com/Arthur-Conti/guh/libs/log/application_package"
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+// SlogHandler lets log/slog calls land in the outputs of a GUH Logger.
+// A "package" attribute overrides the handler's application package.
+type SlogHandler struct {
+	logger             *Logger
+	applicationPackage string
+	prefix             string
+	fields             map[string]any
+}
+
+func NewSlogHandler(l *Logger, applicationPackage string) *SlogHandler {
+	return &SlogHandler{
+		logger:             l,
+		applicationPackage: applicationPackage,
+		fields:             map[string]any{},
+	}
+}
+
+// NewSlogLogger returns a *slog.Logger writing through l.
+func NewSlogLogger(l *Logger, applicationPackage string) *slog.Logger {
+	return slog.New(NewSlogHandler(l, applicationPackage))
+}
+
+// NewFromSlogHandler builds a GUH Logger on top of any slog.Handler; level filtering is left to the handler.
+func NewFromSlogHandler(handler slog.Handler) *Logger {
+	return NewLogger(LoggerOpts{
+		OutputType: outputs.NewSlogOutput(handler),
+		Level:      loglevels.TraceLevel,
+	})
+}
+
+func (sh *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return sh.logger.Enabled(sh.applicationPackage, loglevels.FromSlogLevel(level))
+}
+
+func (sh *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(map[string]any, len(sh.fields)+record.NumAttrs())
+	for key, value := range contextFields(ctx) {
+		fields[key] = value
+	}
+	for key, value := range sh.fields {
+		fields[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(fields, sh.prefix, attr)
+		return true
+	})
+
+	applicationPackage := sh.applicationPackage
+	if pkg, ok := fields["package"].(string); ok {
+		applicationPackage = pkg
+		delete(fields, "package")
+	}
+
+	if applicationPackage == "" && sh.logger.opts.ApplicationPackage.AutoDetect() {
+		applicationPackage = applicationpackage.FromPC(record.PC)
+	}
+
+	level := loglevels.FromSlogLevel(record.Level)
+	if !sh.logger.Enabled(applicationPackage, level) {
+		return nil
+	}
+	sh.logger.emit(sh.logger.traced(ctx, outputs.Entry{
+		Time:    record.Time,
+		Level:   level,
+		Package: applicationPackage,
+		Message: record.Message,
+		Fields:  sh.logger.mergeFields(fields),
+		PC:      record.PC,
+	}), record.Message)
+	return nil
+}
+
+func (sh *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := sh.clone()
+	for _, attr := range attrs {
+		addAttr(clone.fields, clone.prefix, attr)
+	}
+	if pkg, ok := clone.fields["package"].(string); ok && clone.prefix == "" {
+		clone.applicationPackage = pkg
+		delete(clone.fields, "package")
+	}
+	return clone
+}
+
+func (sh *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return sh
+	}
+	clone := sh.clone()
+	clone.prefix = sh.prefix + name + "."
+	return clone
+}
+
+func (sh *SlogHandler) clone() *SlogHandler {
+	fields := make(map[string]any, len(sh.fields))
+	for key, value := range sh.fields {
+		fields[key] = value
+	}
+	return &SlogHandler{
+		logger:             sh.logger,
+		applicationPackage: sh.applicationPackage,
+		prefix:             sh.prefix,
+		fields:             fields,
+	}
+}
+
+func addAttr(fields map[string]any, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			addAttr(fields, groupPrefix, groupAttr)
+		}
+		return
+	}
+	fields[strings.TrimSuffix(prefix+attr.Key, ".")] = attr.Value.Any()
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+	"github.com/Arthur-Conti/guh/libs/log/outputs/outputstest"
+)
+
+func TestSlogHandler(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out, LevelStr: "info,db=debug"})
+	log := NewSlogLogger(l.With(map[string]any{"service": "orders"}), "api")
+
+	log.Debug("hidden debug")
+	log.Info("order created", "id", 7, slog.Group("user", "name", "ana", slog.Group("org", "id", 3)))
+	log.With("package", "db").Debug("query done", "rows", 2)
+	log.WithGroup("http").With("method", "GET").Warn("slow request", "ms", 900)
+	log.Error("failed", "package", "cli", "err", "boom")
+
+	outputstest.AssertNotLogged(t, out, loglevels.DebugLevel, "hidden debug")
+	entries := out.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4: %+v", len(entries), entries)
+	}
+	tests := []struct {
+		level   loglevels.LogLevel
+		pkg     string
+		message string
+		fields  map[string]any
+	}{
+		{loglevels.InfoLevel, "api", "order created", map[string]any{"service": "orders", "id": int64(7), "user.name": "ana", "user.org.id": int64(3)}},
+		{loglevels.DebugLevel, "db", "query done", map[string]any{"service": "orders", "rows": int64(2)}},
+		{loglevels.WarningLevel, "api", "slow request", map[string]any{"service": "orders", "http.method": "GET", "http.ms": int64(900)}},
+		{loglevels.ErrorLevel, "cli", "failed", map[string]any{"service": "orders", "err": "boom"}},
+	}
+	for i, tt := range tests {
+		entry := entries[i]
+		if entry.Level != tt.level || entry.Package != tt.pkg || entry.Message != tt.message {
+			t.Errorf("entry %d = %v %q %q, want %v %q %q", i, entry.Level, entry.Package, entry.Message, tt.level, tt.pkg, tt.message)
+		}
+		if len(entry.Fields) != len(tt.fields) {
+			t.Errorf("entry %d fields = %v, want %v", i, entry.Fields, tt.fields)
+		}
+		for key, value := range tt.fields {
+			if entry.Fields[key] != value {
+				t.Errorf("entry %d field %q = %#v, want %#v", i, key, entry.Fields[key], value)
+			}
+		}
+		if entry.PC == 0 {
+			t.Errorf("entry %d has no caller", i)
+		}
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	l := NewLogger(LoggerOpts{OutputType: outputs.NewMemoryOutput(), LevelStr: "warning,db=debug"})
+	ctx := context.Background()
+	tests := []struct {
+		pkg   string
+		level slog.Level
+		want  bool
+	}{
+		{"api", slog.LevelInfo, false},
+		{"api", slog.LevelWarn, true},
+		{"db", slog.LevelDebug, true},
+		{"db.query", slog.LevelDebug - 4, false},
+	}
+	for _, tt := range tests {
+		if got := NewSlogHandler(l, tt.pkg).Enabled(ctx, tt.level); got != tt.want {
+			t.Errorf("Enabled(%q, %v) = %v, want %v", tt.pkg, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSlogHandlerContext(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	log := NewSlogLogger(NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel}), "api")
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithSpanContext(ctx, SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Flags: 1})
+
+	log.InfoContext(ctx, "handled", "password", "hunter2")
+	entry := out.Entries()[0]
+	if entry.Fields[RequestIDField] != "req-1" || entry.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || entry.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("entry = %+v, want the request and trace IDs from ctx", entry)
+	}
+	if entry.Fields["password"] == "hunter2" {
+		t.Errorf("password was not redacted: %v", entry.Fields)
+	}
+}
+
+func TestNewFromSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewFromSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	l.Debug(LogMessage{ApplicationPackage: "db", Message: "hidden"})
+	l.Warningf(LogMessage{ApplicationPackage: "db", Message: "retry %d", Vals: []any{2}, Fields: map[string]any{"table": "users"}})
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("want exactly one JSON record, got %q: %v", buf.String(), err)
+	}
+	want := map[string]any{"level": "WARN", "msg": "retry 2", "package": "db", "table": "users"}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("record[%q] = %v, want %v", key, record[key], value)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+const TraceparentHeader = "traceparent"
+
+// SpanContext holds the W3C trace context identifiers as lowercase hex strings.
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+	Flags   byte
+}
+
+func (sc SpanContext) IsValid() bool {
+	return isHexID(sc.TraceID, 32) && isHexID(sc.SpanID, 16)
+}
+
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&0x01 == 0x01
+}
+
+// Traceparent renders sc as a version 00 traceparent header.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// NewSpanContext starts a new sampled trace, for requests arriving without a traceparent.
+func NewSpanContext() SpanContext {
+	return SpanContext{TraceID: randomHex(16), SpanID: randomHex(8), Flags: 0x01}
+}
+
+// ChildSpan keeps the trace ID and flags with a new span ID.
+func (sc SpanContext) ChildSpan() SpanContext {
+	return SpanContext{TraceID: sc.TraceID, SpanID: randomHex(8), Flags: sc.Flags}
+}
+
+func ParseTraceparent(header string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, errorhandler.New(errorhandler.KindInvalidArgument, "malformed traceparent", errorhandler.WithOp("logger.ParseTraceparent"), errorhandler.WithFields(map[string]any{"traceparent": header}))
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return SpanContext{}, errorhandler.New(errorhandler.KindInvalidArgument, "malformed traceparent flags", errorhandler.WithOp("logger.ParseTraceparent"), errorhandler.WithFields(map[string]any{"traceparent": header}))
+	}
+	sc := SpanContext{TraceID: strings.ToLower(parts[1]), SpanID: strings.ToLower(parts[2]), Flags: flags[0]}
+	if !sc.IsValid() {
+		return SpanContext{}, errorhandler.New(errorhandler.KindInvalidArgument, "invalid trace or span id", errorhandler.WithOp("logger.ParseTraceparent"), errorhandler.WithFields(map[string]any{"traceparent": header}))
+	}
+	return sc, nil
+}
+
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, traceKey, sc)
+}
+
+// ContextWithTraceparent stores the span context parsed from a traceparent header in ctx.
+func ContextWithTraceparent(ctx context.Context, header string) (context.Context, error) {
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		return ctx, err
+	}
+	return WithSpanContext(ctx, sc), nil
+}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []func(context.Context) (SpanContext, bool)
+)
+
+// RegisterSpanContextExtractor lets a tracing SDK (e.g. OpenTelemetry) supply the active span
+// when the context has none stored with WithSpanContext.
+func RegisterSpanContextExtractor(extractor func(context.Context) (SpanContext, bool)) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, extractor)
+}
+
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	if sc, ok := ctx.Value(traceKey).(SpanContext); ok && sc.IsValid() {
+		return sc, true
+	}
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	for _, extractor := range extractors {
+		if sc, ok := extractor(ctx); ok && sc.IsValid() {
+			return sc, true
+		}
+	}
+	return SpanContext{}, false
+}
+
+func isHexID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
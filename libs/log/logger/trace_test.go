@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   SpanContext
+		ok     bool
+	}{
+		{"sampled", "00-" + testTraceID + "-" + testSpanID + "-01", SpanContext{TraceID: testTraceID, SpanID: testSpanID, Flags: 1}, true},
+		{"not sampled", "00-" + testTraceID + "-" + testSpanID + "-00", SpanContext{TraceID: testTraceID, SpanID: testSpanID}, true},
+		{"upper case and spaces", " 00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01 ", SpanContext{TraceID: testTraceID, SpanID: testSpanID, Flags: 1}, true},
+		{"future version with extra parts", "01-" + testTraceID + "-" + testSpanID + "-01-extra", SpanContext{TraceID: testTraceID, SpanID: testSpanID, Flags: 1}, true},
+		{"empty", "", SpanContext{}, false},
+		{"version ff", "ff-" + testTraceID + "-" + testSpanID + "-01", SpanContext{}, false},
+		{"version 00 with extra parts", "00-" + testTraceID + "-" + testSpanID + "-01-extra", SpanContext{}, false},
+		{"zero trace id", "00-00000000000000000000000000000000-" + testSpanID + "-01", SpanContext{}, false},
+		{"zero span id", "00-" + testTraceID + "-0000000000000000-01", SpanContext{}, false},
+		{"short trace id", "00-4bf92f35-" + testSpanID + "-01", SpanContext{}, false},
+		{"non hex span id", "00-" + testTraceID + "-zzf067aa0ba902b7-01", SpanContext{}, false},
+		{"bad flags", "00-" + testTraceID + "-" + testSpanID + "-1", SpanContext{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTraceparent(tt.header)
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Fatalf("ParseTraceparent(%q) = %+v, %v, want %+v", tt.header, got, err, tt.want)
+			}
+			if err != nil && !errorhandler.IsKind(err, errorhandler.KindInvalidArgument) {
+				t.Errorf("error kind = %v, want invalid argument", err)
+			}
+		})
+	}
+}
+
+func TestSpanContext(t *testing.T) {
+	sc := SpanContext{TraceID: testTraceID, SpanID: testSpanID, Flags: 1}
+	if got := sc.Traceparent(); got != "00-"+testTraceID+"-"+testSpanID+"-01" {
+		t.Errorf("Traceparent() = %q", got)
+	}
+	if parsed, err := ParseTraceparent(sc.Traceparent()); err != nil || parsed != sc {
+		t.Errorf("round trip = %+v, %v", parsed, err)
+	}
+	if !sc.Sampled() || (SpanContext{Flags: 2}).Sampled() {
+		t.Error("Sampled() does not follow the 01 flag")
+	}
+
+	child := sc.ChildSpan()
+	if !child.IsValid() || child.TraceID != sc.TraceID || child.Flags != sc.Flags || child.SpanID == sc.SpanID {
+		t.Errorf("ChildSpan() = %+v, want the same trace with a new span", child)
+	}
+	fresh := NewSpanContext()
+	if !fresh.IsValid() || !fresh.Sampled() || fresh.TraceID == NewSpanContext().TraceID {
+		t.Errorf("NewSpanContext() = %+v, want a new valid sampled trace", fresh)
+	}
+}
+
+type otelSpanKey struct{}
+
+func TestSpanContextFromContext(t *testing.T) {
+	RegisterSpanContextExtractor(func(ctx context.Context) (SpanContext, bool) {
+		sc, ok := ctx.Value(otelSpanKey{}).(SpanContext)
+		return sc, ok
+	})
+	stored := SpanContext{TraceID: testTraceID, SpanID: testSpanID, Flags: 1}
+	extracted := SpanContext{TraceID: testTraceID, SpanID: "1111111111111111"}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want SpanContext
+		ok   bool
+	}{
+		{"nil", nil, SpanContext{}, false},
+		{"empty", context.Background(), SpanContext{}, false},
+		{"stored", WithSpanContext(context.Background(), stored), stored, true},
+		{"extractor", context.WithValue(context.Background(), otelSpanKey{}, extracted), extracted, true},
+		{"stored wins over extractor", WithSpanContext(context.WithValue(context.Background(), otelSpanKey{}, extracted), stored), stored, true},
+		{"invalid stored falls back to extractor", WithSpanContext(context.WithValue(context.Background(), otelSpanKey{}, extracted), SpanContext{}), extracted, true},
+		{"invalid extracted", context.WithValue(context.Background(), otelSpanKey{}, SpanContext{TraceID: "x"}), SpanContext{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SpanContextFromContext(tt.ctx)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("SpanContextFromContext() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	ctx, err := ContextWithTraceparent(context.Background(), stored.Traceparent())
+	if got, _ := SpanContextFromContext(ctx); err != nil || got != stored {
+		t.Errorf("ContextWithTraceparent() = %+v, %v", got, err)
+	}
+	if _, err := ContextWithTraceparent(context.Background(), "garbage"); err == nil {
+		t.Error("ContextWithTraceparent(garbage) did not fail")
+	}
+}
+
+func TestEntryTraceIDs(t *testing.T) {
+	out := outputs.NewMemoryOutput()
+	l := NewLogger(LoggerOpts{OutputType: out, Level: loglevels.InfoLevel})
+	span := SpanContext{TraceID: testTraceID, SpanID: testSpanID, Flags: 1}
+	other := SpanContext{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331", Flags: 1}
+	ctx := WithSpanContext(context.Background(), span)
+
+	l.Info(LogMessage{Message: "no trace"})
+	l.Info(LogMessage{Message: "message context", Context: ctx})
+	FromContext(WithContext(ctx, l)).Info(LogMessage{Message: "from context"})
+	FromContext(WithContext(ctx, l)).Info(LogMessage{Message: "message context wins", Context: WithSpanContext(context.Background(), other)})
+
+	want := []SpanContext{{}, span, span, other}
+	entries := out.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, sc := range want {
+		if entries[i].TraceID != sc.TraceID || entries[i].SpanID != sc.SpanID {
+			t.Errorf("%q: trace %q span %q, want %q %q", entries[i].Message, entries[i].TraceID, entries[i].SpanID, sc.TraceID, sc.SpanID)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package outputs
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+type Entry struct {
+	Time       time.Time
+	Level      loglevels.LogLevel
+	Package    string
+	PackageTag string
+	// PackageColor is the ANSI colour of PackageTag, only applied by coloured plain outputs.
+	PackageColor string
+	Message      string
+	Fields       map[string]any
+	// PC is the program counter of the logging call site, zero when unknown.
+	PC uintptr
+	// TraceID and SpanID are the W3C trace context of the entry, empty when unknown.
+	TraceID string
+	SpanID  string
+}
+
+func (e Entry) Caller() (runtime.Frame, bool) {
+	if e.PC == 0 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{e.PC}).Next()
+	return frame, frame.File != ""
+}
+
+func (e Entry) SortedFieldKeys() []string {
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatTrace(entry Entry) string {
+	if entry.TraceID == "" {
+		return ""
+	}
+	return " trace_id=" + entry.TraceID + " span_id=" + entry.SpanID
+}
+
+func formatFields(entry Entry) string {
+	if len(entry.Fields) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, key := range entry.SortedFieldKeys() {
+		value := fmt.Sprint(entry.Fields[key])
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		sb.WriteString(" " + key + "=" + value)
+	}
+	return sb.String()
+}
+
+type EntryRecord struct {
+	Time    time.Time          `json:"time"`
+	Level   loglevels.LogLevel `json:"level"`
+	Package string             `json:"package,omitempty"`
+	Message string             `json:"message"`
+	Caller  string             `json:"caller,omitempty"`
+	Fields  map[string]any     `json:"fields,omitempty"`
+	TraceID string             `json:"trace_id,omitempty"`
+	SpanID  string             `json:"span_id,omitempty"`
+}
+
+// Record is the serialisable form of an entry used by the JSON based outputs.
+func (e Entry) Record() EntryRecord {
+	record := EntryRecord{
+		Time:    e.Time,
+		Level:   e.Level,
+		Package: e.Package,
+		Message: e.Message,
+		Fields:  e.Fields,
+		TraceID: e.TraceID,
+		SpanID:  e.SpanID,
+	}
+	if e.Package == "" {
+		record.Message = e.PackageTag + e.Message
+	}
+	if frame, ok := e.Caller(); ok {
+		record.Caller = fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+	}
+	return record
+}
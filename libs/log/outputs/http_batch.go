@@ -0,0 +1,211 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	retryhandler "github.com/Arthur-Conti/guh/libs/retry_handler"
+)
+
+type HTTPBatchOutputOpts struct {
+	URL string
+	// BatchSize defaults to 100 entries.
+	BatchSize int
+	// MaxBuffer caps the entries waiting to be sent, defaults to 10 batches. The oldest entries
+	// are dropped when it is full, e.g. while the endpoint is down.
+	MaxBuffer int
+	// FlushInterval defaults to 5 seconds.
+	FlushInterval time.Duration
+	Headers       map[string]string
+	Client        *http.Client
+	// Retry defaults to 3 attempts with exponential backoff from 1 second; 4xx responses are not retried.
+	Retry retryhandler.Policy
+	// Encoder renders a batch as the request body, defaults to NDJSON of Entry.Record().
+	Encoder func([]Entry) ([]byte, error)
+	// ContentType defaults to application/x-ndjson.
+	ContentType string
+}
+
+// HTTPBatchOutput buffers entries and POSTs them as NDJSON batches. Entries logged after
+// Close are dropped.
+type HTTPBatchOutput struct {
+	opts     HTTPBatchOutputOpts
+	mu       sync.Mutex
+	buffer   []Entry
+	stopped  bool
+	dropped  int
+	reported int
+	flushCh  chan struct{}
+	done     chan struct{}
+	closed   sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewHTTPBatchOutput(opts HTTPBatchOutputOpts) *HTTPBatchOutput {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.MaxBuffer <= 0 {
+		opts.MaxBuffer = 10 * opts.BatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.Encoder == nil {
+		opts.Encoder = encodeNDJSON
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = "application/x-ndjson"
+	}
+	if opts.Retry.MaxAttempts <= 0 {
+		opts.Retry = retryhandler.Policy{MaxAttempts: 3, InitialDelay: time.Second, Jitter: retryhandler.JitterEqual}
+	}
+	ho := &HTTPBatchOutput{
+		opts:    opts,
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	ho.wg.Add(1)
+	go ho.run()
+	return ho
+}
+
+func (ho *HTTPBatchOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	ho.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
+}
+
+func (ho *HTTPBatchOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	ho.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (ho *HTTPBatchOutput) LogEntry(entry Entry) {
+	ho.mu.Lock()
+	if ho.stopped {
+		ho.dropped++
+		ho.mu.Unlock()
+		return
+	}
+	if len(ho.buffer) >= ho.opts.MaxBuffer {
+		ho.buffer = ho.buffer[1:]
+		ho.dropped++
+	}
+	ho.buffer = append(ho.buffer, entry)
+	full := len(ho.buffer) >= ho.opts.BatchSize
+	ho.mu.Unlock()
+	if full {
+		select {
+		case ho.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush sends the buffered entries synchronously and reports entries dropped since the
+// last Flush.
+func (ho *HTTPBatchOutput) Flush() error {
+	ho.mu.Lock()
+	batch := ho.buffer
+	ho.buffer = nil
+	dropped := ho.dropped - ho.reported
+	ho.reported = ho.dropped
+	ho.mu.Unlock()
+	if dropped > 0 {
+		reportError("http batch", fmt.Errorf("dropped %d entries", dropped))
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return ho.send(batch)
+}
+
+// Close stops the background flusher and sends what is left in the buffer.
+func (ho *HTTPBatchOutput) Close() error {
+	ho.closed.Do(func() { close(ho.done) })
+	ho.wg.Wait()
+	ho.mu.Lock()
+	ho.stopped = true
+	ho.mu.Unlock()
+	return ho.Flush()
+}
+
+// Dropped returns how many entries were dropped in total, because the buffer was full or
+// they were logged after Close.
+func (ho *HTTPBatchOutput) Dropped() int {
+	ho.mu.Lock()
+	defer ho.mu.Unlock()
+	return ho.dropped
+}
+
+func (ho *HTTPBatchOutput) run() {
+	defer ho.wg.Done()
+	ticker := time.NewTicker(ho.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ho.done:
+			return
+		case <-ticker.C:
+		case <-ho.flushCh:
+		}
+		if err := ho.Flush(); err != nil {
+			reportError("http batch", err)
+		}
+	}
+}
+
+func (ho *HTTPBatchOutput) send(batch []Entry) error {
+	payload, err := ho.opts.Encoder(batch)
+	if err != nil {
+		return errorhandler.Wrap(errorhandler.KindInternal, "Error encoding log batch", err, errorhandler.WithOp("outputs.HTTPBatchOutput.send"))
+	}
+
+	return retryhandler.Do(context.Background(), ho.opts.Retry, func(ctx context.Context) error {
+		return ho.post(ctx, payload)
+	})
+}
+
+func (ho *HTTPBatchOutput) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ho.opts.URL, bytes.NewReader(payload))
+	if err != nil {
+		return errorhandler.Wrap(errorhandler.KindInvalidArgument, "Error creating log batch request", err, errorhandler.WithOp("outputs.HTTPBatchOutput.post"), errorhandler.WithFields(map[string]any{"url": ho.opts.URL}))
+	}
+	req.Header.Set("Content-Type", ho.opts.ContentType)
+	for key, value := range ho.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := ho.opts.Client.Do(req)
+	if err != nil {
+		return errorhandler.Wrap(errorhandler.KindUnavailable, "Error sending log batch", err, errorhandler.WithOp("outputs.HTTPBatchOutput.post"), errorhandler.WithFields(map[string]any{"url": ho.opts.URL}))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return errorhandler.New(errorhandler.KindUnavailable, fmt.Sprintf("HTTP %d", resp.StatusCode), errorhandler.WithOp("outputs.HTTPBatchOutput.post"), errorhandler.WithFields(map[string]any{"url": ho.opts.URL}))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errorhandler.New(errorhandler.KindInvalidArgument, fmt.Sprintf("HTTP %d", resp.StatusCode), errorhandler.WithOp("outputs.HTTPBatchOutput.post"), errorhandler.WithFields(map[string]any{"url": ho.opts.URL}))
+	}
+	return nil
+}
+
+func encodeNDJSON(batch []Entry) ([]byte, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, entry := range batch {
+		if err := encoder.Encode(entry.Record()); err != nil {
+			return nil, err
+		}
+	}
+	return body.Bytes(), nil
+}
@@ -0,0 +1,116 @@
+package outputs
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+// batchServer records the messages of every NDJSON batch it receives.
+type batchServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	batches  int
+	messages []string
+}
+
+func newBatchServer(t *testing.T) *batchServer {
+	bs := &batchServer{}
+	bs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scanner := bufio.NewScanner(r.Body)
+		bs.mu.Lock()
+		defer bs.mu.Unlock()
+		bs.batches++
+		for scanner.Scan() {
+			var record EntryRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Errorf("invalid NDJSON line %q: %v", scanner.Text(), err)
+			}
+			bs.messages = append(bs.messages, record.Message)
+		}
+	}))
+	t.Cleanup(bs.Close)
+	return bs
+}
+
+func (bs *batchServer) received() (int, []string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	return bs.batches, append([]string(nil), bs.messages...)
+}
+
+func logMessages(ho *HTTPBatchOutput, messages ...string) {
+	for _, message := range messages {
+		ho.LogEntry(Entry{Time: time.Now(), Level: loglevels.InfoLevel, Message: message})
+	}
+}
+
+func TestHTTPBatchOutputSendsBatches(t *testing.T) {
+	server := newBatchServer(t)
+	ho := NewHTTPBatchOutput(HTTPBatchOutputOpts{URL: server.URL, BatchSize: 2, FlushInterval: time.Hour})
+	logMessages(ho, "a", "b")
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if batches, _ := server.received(); batches == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("a full batch was not sent")
+		}
+	}
+	logMessages(ho, "c")
+	if err := ho.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	batches, messages := server.received()
+	if batches != 2 || len(messages) != 3 || messages[0] != "a" || messages[2] != "c" {
+		t.Errorf("received %d batches %q, want 2 batches [a b c]", batches, messages)
+	}
+}
+
+func TestHTTPBatchOutputDropsOldest(t *testing.T) {
+	server := newBatchServer(t)
+	ho := NewHTTPBatchOutput(HTTPBatchOutputOpts{URL: server.URL, BatchSize: 100, MaxBuffer: 3, FlushInterval: time.Hour})
+	logMessages(ho, "1", "2", "3", "4", "5")
+	if got := ho.Dropped(); got != 2 {
+		t.Errorf("Dropped() = %d, want 2", got)
+	}
+	ho.Close()
+	if _, messages := server.received(); len(messages) != 3 || messages[0] != "3" || messages[2] != "5" {
+		t.Errorf("received %q, want the newest [3 4 5]", messages)
+	}
+}
+
+func TestHTTPBatchOutputDropsAfterClose(t *testing.T) {
+	server := newBatchServer(t)
+	ho := NewHTTPBatchOutput(HTTPBatchOutputOpts{URL: server.URL, FlushInterval: time.Hour})
+	ho.Close()
+	logMessages(ho, "late")
+	if err := ho.Flush(); err != nil {
+		t.Fatalf("Flush() = %v", err)
+	}
+	if got := ho.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+	if batches, _ := server.received(); batches != 0 {
+		t.Errorf("received %d batches after Close, want none", batches)
+	}
+}
+
+func TestHTTPBatchOutputDefaultMaxBuffer(t *testing.T) {
+	ho := NewHTTPBatchOutput(HTTPBatchOutputOpts{URL: "http://127.0.0.1:0", BatchSize: 7})
+	defer func() {
+		ho.mu.Lock()
+		ho.buffer = nil
+		ho.mu.Unlock()
+		ho.Close()
+	}()
+	if ho.opts.MaxBuffer != 70 {
+		t.Errorf("MaxBuffer = %d, want 10 batches", ho.opts.MaxBuffer)
+	}
+}
@@ -6,3 +6,9 @@ type OutputInterface interface {
 	Log(string, loglevels.LogLevel, string)
 	Logf(string, loglevels.LogLevel, string, ...any)
 }
+
+// EntryOutput is implemented by outputs that want the whole entry (fields, time, raw package)
+// instead of the pre-rendered package tag and message.
+type EntryOutput interface {
+	LogEntry(Entry)
+}
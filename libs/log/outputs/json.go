@@ -3,7 +3,10 @@ package outputs
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sync"
+	"time"
 
 	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
 )
@@ -11,10 +14,15 @@ import (
 type JsonMessage struct {
 	LogLevel loglevels.LogLevel
 	Message  string
+	Fields   map[string]any `json:",omitempty"`
+	TraceID  string         `json:",omitempty"`
+	SpanID   string         `json:",omitempty"`
 }
 
 type JsonOutput struct {
-	file string
+	file   string
+	writer io.Writer
+	mu     sync.Mutex
 }
 
 func NewJsonOutput(filePath, fileName string) *JsonOutput {
@@ -23,7 +31,18 @@ func NewJsonOutput(filePath, fileName string) *JsonOutput {
 	}
 }
 
+// NewJsonWriterOutput writes one JSON record per line (NDJSON) to w, e.g. os.Stdout.
+func NewJsonWriterOutput(w io.Writer) *JsonOutput {
+	return &JsonOutput{
+		writer: w,
+	}
+}
+
 func (jo *JsonOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	if jo.writer != nil {
+		jo.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
+		return
+	}
 	jsonMessage := JsonMessage{
 		LogLevel: level,
 		Message:  applicationPackage + message,
@@ -34,6 +53,10 @@ func (jo *JsonOutput) Log(applicationPackage string, level loglevels.LogLevel, m
 }
 
 func (jo *JsonOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	if jo.writer != nil {
+		jo.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+		return
+	}
 	jsonMessage := JsonMessage{
 		LogLevel: level,
 		Message:  fmt.Sprintf(applicationPackage+message, vals...),
@@ -43,6 +66,30 @@ func (jo *JsonOutput) Logf(applicationPackage string, level loglevels.LogLevel,
 	}
 }
 
+func (jo *JsonOutput) LogEntry(entry Entry) {
+	if jo.writer != nil {
+		data, err := json.Marshal(entry.Record())
+		if err != nil {
+			reportError("json", err)
+			return
+		}
+		jo.mu.Lock()
+		defer jo.mu.Unlock()
+		jo.writer.Write(append(data, '\n'))
+		return
+	}
+	jsonMessage := JsonMessage{
+		LogLevel: entry.Level,
+		Message:  entry.PackageTag + entry.Message,
+		Fields:   entry.Fields,
+		TraceID:  entry.TraceID,
+		SpanID:   entry.SpanID,
+	}
+	if err := jsonEncoder(jsonMessage, jo.file); err != nil {
+		panic(err)
+	}
+}
+
 func jsonEncoder(message JsonMessage, file string) error {
 	var logs []JsonMessage
 
@@ -0,0 +1,79 @@
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+type LineFormat int
+
+const (
+	// LineFormatJSON writes one EntryRecord per line (NDJSON).
+	LineFormatJSON LineFormat = iota
+	// LineFormatText writes "time level package message key=value...".
+	LineFormatText
+)
+
+type LineOutputOpts struct {
+	// Network is "tcp", "udp" or "unix".
+	Network     string
+	Address     string
+	Format      LineFormat
+	DialTimeout time.Duration
+	// QueueSize is the number of entries waiting to be sent, defaults to 1024. Entries are
+	// dropped when it is full.
+	QueueSize int
+}
+
+// LineOutput writes one line per entry to a TCP/UDP/unix listener (Logstash, Vector, Fluent Bit...).
+type LineOutput struct {
+	opts LineOutputOpts
+	conn *netConn
+}
+
+func NewLineOutput(opts LineOutputOpts) *LineOutput {
+	if opts.Network == "" {
+		opts.Network = "tcp"
+	}
+	return &LineOutput{
+		opts: opts,
+		conn: newNetConn("line", opts.Network, opts.Address, opts.DialTimeout, opts.QueueSize),
+	}
+}
+
+func (lo *LineOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	lo.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
+}
+
+func (lo *LineOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	lo.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (lo *LineOutput) LogEntry(entry Entry) {
+	line, err := lo.format(entry)
+	if err != nil {
+		reportError("line", err)
+		return
+	}
+	if err := lo.conn.write(append(line, '\n')); err != nil {
+		reportError("line", err)
+	}
+}
+
+func (lo *LineOutput) Close() error {
+	return lo.conn.close()
+}
+
+func (lo *LineOutput) format(entry Entry) ([]byte, error) {
+	if lo.opts.Format == LineFormatText {
+		pkg := entry.Package
+		if pkg == "" {
+			pkg = "-"
+		}
+		return []byte(fmt.Sprintf("%s %s %s %q%s", entry.Time.Format(time.RFC3339Nano), entry.Level, pkg, entry.Message, formatFields(entry)+formatTrace(entry))), nil
+	}
+	return json.Marshal(entry.Record())
+}
@@ -0,0 +1,46 @@
+package outputs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+// MemoryOutput records entries so tests can assert on logging without scraping stdout, see
+// the outputstest package for assertions.
+type MemoryOutput struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{}
+}
+
+func (mo *MemoryOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	mo.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
+}
+
+func (mo *MemoryOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	mo.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (mo *MemoryOutput) LogEntry(entry Entry) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	mo.entries = append(mo.entries, entry)
+}
+
+func (mo *MemoryOutput) Entries() []Entry {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	return append([]Entry(nil), mo.entries...)
+}
+
+func (mo *MemoryOutput) Reset() {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	mo.entries = nil
+}
@@ -0,0 +1,49 @@
+package outputs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+// MultiOutput fans every entry out to several outputs.
+type MultiOutput struct {
+	outputs []OutputInterface
+}
+
+func NewMultiOutput(outputs ...OutputInterface) *MultiOutput {
+	return &MultiOutput{outputs: outputs}
+}
+
+func (mo *MultiOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	for _, output := range mo.outputs {
+		output.Log(applicationPackage, level, message)
+	}
+}
+
+func (mo *MultiOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	mo.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (mo *MultiOutput) LogEntry(entry Entry) {
+	for _, output := range mo.outputs {
+		if entryOutput, ok := output.(EntryOutput); ok {
+			entryOutput.LogEntry(entry)
+		} else {
+			output.Log(entry.PackageTag, entry.Level, entry.Message)
+		}
+	}
+}
+
+// Close closes every output that implements io.Closer.
+func (mo *MultiOutput) Close() error {
+	var errs []error
+	for _, output := range mo.outputs {
+		if closer, ok := output.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
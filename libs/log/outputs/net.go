@@ -0,0 +1,169 @@
+package outputs
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+	retryhandler "github.com/Arthur-Conti/guh/libs/retry_handler"
+)
+
+const (
+	defaultDialTimeout = 5 * time.Second
+	defaultQueueSize   = 1024
+)
+
+// reconnectPolicy spaces out dial attempts while the remote end is down.
+var reconnectPolicy = retryhandler.Policy{InitialDelay: 100 * time.Millisecond, MaxDelay: 30 * time.Second, Jitter: retryhandler.JitterEqual}
+
+// netConn queues writes for a background goroutine that dials lazily, reconnects once when a
+// write fails and backs off between failed dials. Entries are dropped, never blocking the
+// caller, when the queue is full or while waiting to reconnect.
+type netConn struct {
+	output      string
+	network     string
+	address     string
+	dialTimeout time.Duration
+	queue       chan []byte
+	done        chan struct{}
+	closed      sync.Once
+	wg          sync.WaitGroup
+
+	// Only used by run.
+	conn      net.Conn
+	failures  int
+	retryAt   time.Time
+	dropped   int
+	lastError error
+}
+
+func newNetConn(output, network, address string, dialTimeout time.Duration, queueSize int) *netConn {
+	if dialTimeout <= 0 {
+		dialTimeout = defaultDialTimeout
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	nc := &netConn{
+		output:      output,
+		network:     network,
+		address:     address,
+		dialTimeout: dialTimeout,
+		queue:       make(chan []byte, queueSize),
+		done:        make(chan struct{}),
+	}
+	nc.wg.Add(1)
+	go nc.run()
+	return nc
+}
+
+// write queues b and only fails when the queue is full or the connection is closed.
+func (nc *netConn) write(b []byte) error {
+	select {
+	case <-nc.done:
+		return errorhandler.New(errorhandler.KindFailedPrecondition, "Connection closed", errorhandler.WithOp("outputs.netConn.write"), errorhandler.WithFields(map[string]any{"address": nc.address}))
+	default:
+	}
+	select {
+	case nc.queue <- b:
+		return nil
+	default:
+		return errorhandler.New(errorhandler.KindResourceExhausted, "Queue full, entry dropped", errorhandler.WithOp("outputs.netConn.write"), errorhandler.WithFields(map[string]any{"address": nc.address}))
+	}
+}
+
+// close sends what is left in the queue and closes the connection.
+func (nc *netConn) close() error {
+	nc.closed.Do(func() { close(nc.done) })
+	nc.wg.Wait()
+	if nc.conn == nil {
+		return nil
+	}
+	err := nc.conn.Close()
+	nc.conn = nil
+	return err
+}
+
+func (nc *netConn) run() {
+	defer nc.wg.Done()
+	for {
+		select {
+		case b := <-nc.queue:
+			nc.send(b)
+		case <-nc.done:
+			for {
+				select {
+				case b := <-nc.queue:
+					nc.send(b)
+				default:
+					nc.reportDropped()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (nc *netConn) send(b []byte) {
+	for attempt := 0; attempt < 2; attempt++ {
+		if nc.conn == nil && !nc.dial() {
+			nc.dropped++
+			return
+		}
+		_, err := nc.conn.Write(b)
+		if err == nil {
+			nc.reportDropped()
+			return
+		}
+		nc.lastError = err
+		nc.conn.Close()
+		nc.conn = nil
+	}
+	nc.dropped++
+}
+
+// dial connects unless a previous failure asked to wait, backing off after each failure.
+func (nc *netConn) dial() bool {
+	if time.Now().Before(nc.retryAt) {
+		return false
+	}
+	conn, err := net.DialTimeout(nc.network, nc.address, nc.dialTimeout)
+	if err != nil {
+		nc.failures++
+		nc.retryAt = time.Now().Add(reconnectPolicy.Delay(nc.failures))
+		if nc.failures == 1 {
+			reportError(nc.output, err)
+		}
+		nc.lastError = err
+		return false
+	}
+	nc.conn = conn
+	nc.failures = 0
+	nc.retryAt = time.Time{}
+	return true
+}
+
+// reportDropped reports once how many entries were lost since the last successful write.
+func (nc *netConn) reportDropped() {
+	if nc.dropped == 0 {
+		return
+	}
+	reportError(nc.output, fmt.Errorf("dropped %d entries: %v", nc.dropped, nc.lastError))
+	nc.dropped = 0
+}
+
+func isStreamNetwork(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+// reportError is the last resort for outputs that cannot deliver an entry.
+func reportError(output string, err error) {
+	fmt.Fprintf(os.Stderr, "outputs: %s: %v\n", output, err)
+}
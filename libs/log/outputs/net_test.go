@@ -0,0 +1,173 @@
+package outputs
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+// acceptAll reads everything written to the first connection accepted by listener.
+func acceptAll(t *testing.T, listener net.Listener) <-chan string {
+	t.Helper()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- ""
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- string(data)
+	}()
+	return received
+}
+
+func receive(t *testing.T, received <-chan string) string {
+	t.Helper()
+	select {
+	case data := <-received:
+		return data
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the listener")
+		return ""
+	}
+}
+
+func TestLineOutputTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := acceptAll(t, listener)
+
+	lo := NewLineOutput(LineOutputOpts{Address: listener.Addr().String(), Format: LineFormatText})
+	lo.LogEntry(Entry{Time: time.Now(), Level: loglevels.InfoLevel, Package: "api", Message: "first"})
+	lo.LogEntry(Entry{Time: time.Now(), Level: loglevels.ErrorLevel, Package: "db", Message: "second", Fields: map[string]any{"id": 7}})
+	if err := lo.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(receive(t, received), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), lines)
+	}
+	if !strings.HasSuffix(lines[0], `api "first"`) || !strings.HasSuffix(lines[1], `db "second" id=7`) {
+		t.Errorf("unexpected lines %q", lines)
+	}
+}
+
+func TestSyslogOutputTCPFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := acceptAll(t, listener)
+
+	so := NewSyslogOutput(SyslogOutputOpts{Network: "tcp", Address: listener.Addr().String(), AppName: "app", Hostname: "host"})
+	so.LogEntry(Entry{Time: time.Now(), Level: loglevels.WarningLevel, Package: "api", Message: "slow request"})
+	so.Close()
+
+	reader := bufio.NewReader(strings.NewReader(receive(t, received)))
+	length, err := reader.ReadString(' ')
+	if err != nil {
+		t.Fatalf("missing octet count: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	if err != nil {
+		t.Fatalf("invalid octet count %q", length)
+	}
+	msg, _ := io.ReadAll(reader)
+	if len(msg) != n {
+		t.Errorf("octet count %d, message is %d bytes", n, len(msg))
+	}
+	if !strings.HasPrefix(string(msg), "<12>1 ") || !strings.Contains(string(msg), " host app ") || !strings.HasSuffix(string(msg), " api - slow request") {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func TestSyslogOutputUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	so := NewSyslogOutput(SyslogOutputOpts{Address: conn.LocalAddr().String(), AppName: "app", Hostname: "host"})
+	defer so.Close()
+	so.LogEntry(Entry{Time: time.Now(), Level: loglevels.InfoLevel, Package: "api", Message: "started"})
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<14>1 ") || !strings.Contains(msg, " host app ") || !strings.HasSuffix(msg, " api - started") {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func TestNetConnDropsWhileDownAndReconnects(t *testing.T) {
+	// Reserve a port, then free it so the first dial is refused.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	lo := NewLineOutput(LineOutputOpts{Address: address, Format: LineFormatText})
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		lo.LogEntry(Entry{Time: time.Now(), Level: loglevels.InfoLevel, Package: "api", Message: "lost"})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("logging while down took %v, want it not to block", elapsed)
+	}
+	// Let the writer drop the queued entries before the server comes back.
+	for deadline := time.Now().Add(5 * time.Second); len(lo.conn.queue) > 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Skipf("port %s was reused: %v", address, err)
+	}
+	defer listener.Close()
+	received := acceptAll(t, listener)
+
+	// Wait past the first reconnect backoff.
+	time.Sleep(2 * reconnectPolicy.InitialDelay)
+	lo.LogEntry(Entry{Time: time.Now(), Level: loglevels.InfoLevel, Package: "api", Message: "delivered"})
+	lo.Close()
+
+	data := receive(t, received)
+	if strings.Contains(data, "lost") || !strings.Contains(data, `"delivered"`) {
+		t.Errorf("unexpected data %q", data)
+	}
+}
+
+func TestNetConnQueueFull(t *testing.T) {
+	nc := &netConn{queue: make(chan []byte, 1), done: make(chan struct{})}
+	if err := nc.write([]byte("a")); err != nil {
+		t.Fatalf("first write = %v", err)
+	}
+	if err := nc.write([]byte("b")); err == nil {
+		t.Error("write to a full queue succeeded")
+	}
+	close(nc.done)
+	if err := nc.write([]byte("c")); err == nil {
+		t.Error("write after close succeeded")
+	}
+}
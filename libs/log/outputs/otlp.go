@@ -0,0 +1,203 @@
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+const otlpScopeName = "github.com/Arthur-Conti/guh/libs/log"
+
+var otlpSeverities = map[loglevels.LogLevel]int{
+	loglevels.TraceLevel:   1,
+	loglevels.DebugLevel:   5,
+	loglevels.InfoLevel:    9,
+	loglevels.WarningLevel: 13,
+	loglevels.ErrorLevel:   17,
+	loglevels.FatalLevel:   21,
+}
+
+type OTLPResource struct {
+	ServiceName string
+	Attributes  map[string]string
+}
+
+type OTLPOutputOpts struct {
+	// Writer receives one OTLP/JSON ExportLogsServiceRequest per line, e.g. a file.
+	Writer   io.Writer
+	Resource OTLPResource
+}
+
+// OTLPOutput writes entries in the OTLP/JSON log data model so they can be replayed into a collector.
+type OTLPOutput struct {
+	opts OTLPOutputOpts
+	mu   sync.Mutex
+}
+
+func NewOTLPOutput(opts OTLPOutputOpts) *OTLPOutput {
+	return &OTLPOutput{opts: opts}
+}
+
+// NewOTLPHTTPOutput batches entries and POSTs them to an OTLP/HTTP JSON endpoint such as
+// http://localhost:4318/v1/logs.
+func NewOTLPHTTPOutput(url string, resource OTLPResource, opts HTTPBatchOutputOpts) *HTTPBatchOutput {
+	opts.URL = url
+	opts.ContentType = "application/json"
+	opts.Encoder = func(entries []Entry) ([]byte, error) {
+		return EncodeOTLP(entries, resource)
+	}
+	return NewHTTPBatchOutput(opts)
+}
+
+func (oo *OTLPOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	oo.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
+}
+
+func (oo *OTLPOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	oo.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (oo *OTLPOutput) LogEntry(entry Entry) {
+	data, err := EncodeOTLP([]Entry{entry}, oo.opts.Resource)
+	if err != nil {
+		reportError("otlp", err)
+		return
+	}
+	oo.mu.Lock()
+	defer oo.mu.Unlock()
+	if _, err := oo.opts.Writer.Write(append(data, '\n')); err != nil {
+		reportError("otlp", err)
+	}
+}
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResourceAttrs `json:"resource"`
+	ScopeLogs []otlpScopeLogs   `json:"scopeLogs"`
+}
+
+type otlpResourceAttrs struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// EncodeOTLP renders entries as a single OTLP/JSON ExportLogsServiceRequest.
+func EncodeOTLP(entries []Entry, resource OTLPResource) ([]byte, error) {
+	records := make([]otlpLogRecord, 0, len(entries))
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	for _, entry := range entries {
+		record := entry.Record()
+		logRecord := otlpLogRecord{
+			TimeUnixNano:         strconv.FormatInt(entry.Time.UnixNano(), 10),
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       otlpSeverities[entry.Level],
+			SeverityText:         otlpSeverityText(entry.Level),
+			Body:                 otlpValue(record.Message),
+			TraceID:              entry.TraceID,
+			SpanID:               entry.SpanID,
+		}
+		if entry.Package != "" {
+			logRecord.Attributes = append(logRecord.Attributes, otlpKeyValue{Key: "package", Value: otlpValue(entry.Package)})
+		}
+		if record.Caller != "" {
+			logRecord.Attributes = append(logRecord.Attributes, otlpKeyValue{Key: "code.caller", Value: otlpValue(record.Caller)})
+		}
+		for _, key := range entry.SortedFieldKeys() {
+			logRecord.Attributes = append(logRecord.Attributes, otlpKeyValue{Key: key, Value: otlpValue(entry.Fields[key])})
+		}
+		records = append(records, logRecord)
+	}
+
+	var attributes []otlpKeyValue
+	if resource.ServiceName != "" {
+		attributes = append(attributes, otlpKeyValue{Key: "service.name", Value: otlpValue(resource.ServiceName)})
+	}
+	for _, key := range (Entry{Fields: stringMap(resource.Attributes)}).SortedFieldKeys() {
+		attributes = append(attributes, otlpKeyValue{Key: key, Value: otlpValue(resource.Attributes[key])})
+	}
+
+	return json.Marshal(otlpRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource:  otlpResourceAttrs{Attributes: attributes},
+		ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: otlpScopeName}, LogRecords: records}},
+	}}})
+}
+
+func otlpSeverityText(level loglevels.LogLevel) string {
+	if level == loglevels.WarningLevel {
+		return "WARN"
+	}
+	return strings.ToUpper(level.String())
+}
+
+func otlpValue(value any) otlpAnyValue {
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int32:
+		s := strconv.FormatInt(int64(v), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float32:
+		f := float64(v)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	}
+	s := fmt.Sprint(value)
+	return otlpAnyValue{StringValue: &s}
+}
+
+func stringMap(values map[string]string) map[string]any {
+	m := make(map[string]any, len(values))
+	for key, value := range values {
+		m[key] = value
+	}
+	return m
+}
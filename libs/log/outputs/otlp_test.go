@@ -0,0 +1,57 @@
+package outputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+func TestOTLPOutput(t *testing.T) {
+	var buf bytes.Buffer
+	oo := NewOTLPOutput(OTLPOutputOpts{Writer: &buf, Resource: OTLPResource{ServiceName: "orders", Attributes: map[string]string{"env": "test"}}})
+	oo.LogEntry(Entry{
+		Time:    time.Unix(1_700_000_000, 5),
+		Level:   loglevels.WarningLevel,
+		Package: "db",
+		Message: "slow query",
+		Fields:  map[string]any{"rows": 3, "ok": true},
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	})
+	oo.LogEntry(Entry{Time: time.Unix(1_700_000_000, 0), Level: loglevels.InfoLevel, Message: "untraced"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one request per entry", len(lines))
+	}
+	var request otlpRequest
+	if err := json.Unmarshal([]byte(lines[0]), &request); err != nil {
+		t.Fatal(err)
+	}
+	resource := request.ResourceLogs[0]
+	if len(resource.Resource.Attributes) != 2 || resource.Resource.Attributes[0].Key != "service.name" || *resource.Resource.Attributes[0].Value.StringValue != "orders" || resource.Resource.Attributes[1].Key != "env" {
+		t.Errorf("resource = %+v", resource.Resource)
+	}
+	record := resource.ScopeLogs[0].LogRecords[0]
+	if record.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || record.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("trace %q span %q", record.TraceID, record.SpanID)
+	}
+	if record.TimeUnixNano != "1700000000000000005" || record.SeverityNumber != 13 || record.SeverityText != "WARN" || *record.Body.StringValue != "slow query" {
+		t.Errorf("record = %+v", record)
+	}
+	attributes := map[string]otlpAnyValue{}
+	for _, kv := range record.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	if *attributes["package"].StringValue != "db" || *attributes["rows"].IntValue != "3" || !*attributes["ok"].BoolValue {
+		t.Errorf("attributes = %+v", record.Attributes)
+	}
+
+	if strings.Contains(lines[1], "traceId") || strings.Contains(lines[1], "spanId") {
+		t.Errorf("untraced entry has trace fields: %s", lines[1])
+	}
+}
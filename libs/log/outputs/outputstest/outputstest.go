@@ -0,0 +1,56 @@
+// Package outputstest provides assertions on the entries recorded by an outputs.MemoryOutput.
+package outputstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+// Find returns the entries at level whose message contains substring.
+func Find(out *outputs.MemoryOutput, level loglevels.LogLevel, substring string) []outputs.Entry {
+	var found []outputs.Entry
+	for _, entry := range out.Entries() {
+		if entry.Level == level && strings.Contains(entry.Message, substring) {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+func Contains(out *outputs.MemoryOutput, level loglevels.LogLevel, substring string) bool {
+	return len(Find(out, level, substring)) > 0
+}
+
+func AssertLogged(t testing.TB, out *outputs.MemoryOutput, level loglevels.LogLevel, substring string) {
+	t.Helper()
+	if !Contains(out, level, substring) {
+		t.Errorf("expected a %v entry containing %q, got:\n%s", level, substring, dump(out))
+	}
+}
+
+func AssertNotLogged(t testing.TB, out *outputs.MemoryOutput, level loglevels.LogLevel, substring string) {
+	t.Helper()
+	if Contains(out, level, substring) {
+		t.Errorf("expected no %v entry containing %q, got:\n%s", level, substring, dump(out))
+	}
+}
+
+func dump(out *outputs.MemoryOutput) string {
+	entries := out.Entries()
+	if len(entries) == 0 {
+		return "  (no entries)"
+	}
+	var sb strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "  %v %s%s", entry.Level, entry.PackageTag, entry.Message)
+		for _, key := range entry.SortedFieldKeys() {
+			fmt.Fprintf(&sb, " %s=%v", key, entry.Fields[key])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
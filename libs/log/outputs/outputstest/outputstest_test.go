@@ -0,0 +1,70 @@
+package outputstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+	"github.com/Arthur-Conti/guh/libs/log/outputs"
+)
+
+// recorder captures failures instead of failing the running test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newOutput() *outputs.MemoryOutput {
+	out := outputs.NewMemoryOutput()
+	out.Log("[api] ", loglevels.WarningLevel, "slow request")
+	out.LogEntry(outputs.Entry{Level: loglevels.ErrorLevel, Message: "db down", Fields: map[string]any{"attempt": 3}})
+	return out
+}
+
+func TestFind(t *testing.T) {
+	out := newOutput()
+	if got := Find(out, loglevels.WarningLevel, "slow"); len(got) != 1 || got[0].PackageTag != "[api] " {
+		t.Errorf("Find() = %#v, want the slow request entry", got)
+	}
+	if got := Find(out, loglevels.ErrorLevel, "slow"); len(got) != 0 {
+		t.Errorf("Find() at another level = %#v, want none", got)
+	}
+	if !Contains(out, loglevels.ErrorLevel, "db") || Contains(out, loglevels.InfoLevel, "db") {
+		t.Error("Contains() does not match on level and substring")
+	}
+}
+
+func TestAssertLogged(t *testing.T) {
+	out := newOutput()
+
+	r := &recorder{TB: t}
+	AssertLogged(r, out, loglevels.WarningLevel, "slow request")
+	AssertNotLogged(r, out, loglevels.InfoLevel, "slow request")
+	if len(r.errors) != 0 {
+		t.Errorf("unexpected failures: %q", r.errors)
+	}
+
+	AssertLogged(r, out, loglevels.InfoLevel, "started")
+	AssertNotLogged(r, out, loglevels.ErrorLevel, "db down")
+	if len(r.errors) != 2 {
+		t.Fatalf("got %d failures, want 2: %q", len(r.errors), r.errors)
+	}
+	if !strings.Contains(r.errors[0], `"started"`) || !strings.Contains(r.errors[0], "[api] slow request") || !strings.Contains(r.errors[1], "db down attempt=3") {
+		t.Errorf("failures do not list the recorded entries: %q", r.errors)
+	}
+}
+
+func TestAssertLoggedNoEntries(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertLogged(r, outputs.NewMemoryOutput(), loglevels.InfoLevel, "anything")
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "(no entries)") {
+		t.Errorf("failures = %q, want one mentioning no entries", r.errors)
+	}
+}
@@ -2,50 +2,228 @@ package outputs
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
 	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
 )
 
+type ColorMode int
+
+const (
+	// ColorAuto colours levels only when the writer is a terminal and NO_COLOR is unset.
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+var levelColors = map[loglevels.LogLevel]string{
+	loglevels.TraceLevel:   "\033[90m",
+	loglevels.DebugLevel:   "\033[36m",
+	loglevels.InfoLevel:    "\033[32m",
+	loglevels.WarningLevel: "\033[33m",
+	loglevels.ErrorLevel:   "\033[31m",
+	loglevels.FatalLevel:   "\033[1;35m",
+}
+
+const colorReset = "\033[0m"
+
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
 type PlainOutput struct {
-	opts PlainOutputOpts
+	opts     PlainOutputOpts
+	template *template.Template
+	colored  bool
+	mu       sync.Mutex
 }
 
 type PlainOutputOpts struct {
+	TracePattern   string
 	DebugPattern   string
 	WarningPattern string
 	InfoPattern    string
 	ErrorPattern   string
+	FatalPattern   string
+	// Writer defaults to os.Stdout.
+	Writer io.Writer
+	// Template is a text/template rendered with PlainTemplateData, e.g.
+	// `{{.Time}} {{pad .Level 7}} {{.PackageTag}}{{.Message}} {{.Caller}}{{.Fields}}`.
+	// When empty entries are printed as "<package><pattern><message><fields>".
+	Template string
+	// TimeFormat defaults to time.RFC3339.
+	TimeFormat string
+	Color      ColorMode
+}
+
+type PlainTemplateData struct {
+	Time       string
+	Level      string
+	Pattern    string
+	Package    string
+	PackageTag string
+	Caller     string
+	Message    string
+	Fields     string
+	TraceID    string
+	SpanID     string
+}
+
+var templateFuncs = template.FuncMap{
+	"pad":     padRight,
+	"padLeft": padLeft,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
 }
 
 func NewPlainOutput(opts PlainOutputOpts) *PlainOutput {
-	return &PlainOutput{
-		opts: opts,
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = time.RFC3339
+	}
+	po := &PlainOutput{
+		opts:    opts,
+		colored: useColor(opts.Color, opts.Writer),
+	}
+	if opts.Template != "" {
+		tmpl, err := template.New("plain").Funcs(templateFuncs).Parse(opts.Template)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "outputs: invalid plain template, using default format: %v\n", err)
+		} else {
+			po.template = tmpl
+		}
 	}
+	return po
 }
 
 func (po *PlainOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
-	switch level {
-	case loglevels.DebugLevel:
-		fmt.Println(applicationPackage + po.opts.DebugPattern + message)
-	case loglevels.WarningLevel:
-		fmt.Println(applicationPackage + po.opts.WarningPattern + message)
-	case loglevels.InfoLevel:
-		fmt.Println(applicationPackage + po.opts.InfoPattern + message)
-	case loglevels.ErrorLevel:
-		fmt.Println(applicationPackage + po.opts.ErrorPattern + message)
-	}
+	po.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
 }
 
 func (po *PlainOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
-	backSlashN := "\n"
+	po.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (po *PlainOutput) LogEntry(entry Entry) {
+	line := po.format(entry)
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	fmt.Fprintln(po.opts.Writer, line)
+}
+
+func (po *PlainOutput) format(entry Entry) string {
+	if po.template == nil {
+		return po.packageTag(entry) + po.colorize(entry.Level, po.pattern(entry.Level)) + entry.Message + formatFields(entry) + formatTrace(entry)
+	}
+	data := PlainTemplateData{
+		Time:       entry.Time.Format(po.opts.TimeFormat),
+		Level:      po.colorize(entry.Level, strings.ToUpper(entry.Level.String())),
+		Pattern:    po.colorize(entry.Level, po.pattern(entry.Level)),
+		Package:    entry.Package,
+		PackageTag: po.packageTag(entry),
+		Message:    entry.Message,
+		Fields:     formatFields(entry),
+		TraceID:    entry.TraceID,
+		SpanID:     entry.SpanID,
+	}
+	if frame, ok := entry.Caller(); ok {
+		data.Caller = fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+	}
+	var sb strings.Builder
+	if err := po.template.Execute(&sb, data); err != nil {
+		return entry.PackageTag + po.pattern(entry.Level) + entry.Message + formatFields(entry) + " (template error: " + err.Error() + ")"
+	}
+	return sb.String()
+}
+
+func (po *PlainOutput) colorize(level loglevels.LogLevel, text string) string {
+	if !po.colored || text == "" {
+		return text
+	}
+	return levelColors[level] + text + colorReset
+}
+
+// packageTag colours the tag without its trailing padding so columns stay aligned.
+func (po *PlainOutput) packageTag(entry Entry) string {
+	if !po.colored || entry.PackageColor == "" {
+		return entry.PackageTag
+	}
+	tag := strings.TrimRight(entry.PackageTag, " ")
+	if tag == "" {
+		return entry.PackageTag
+	}
+	return entry.PackageColor + tag + colorReset + entry.PackageTag[len(tag):]
+}
+
+func (po *PlainOutput) pattern(level loglevels.LogLevel) string {
+	var pattern string
 	switch level {
+	case loglevels.TraceLevel:
+		pattern = po.opts.TracePattern
 	case loglevels.DebugLevel:
-		fmt.Printf(applicationPackage+po.opts.DebugPattern+message+backSlashN, vals...)
+		pattern = po.opts.DebugPattern
 	case loglevels.WarningLevel:
-		fmt.Printf(applicationPackage+po.opts.WarningPattern+message+backSlashN, vals...)
+		pattern = po.opts.WarningPattern
 	case loglevels.InfoLevel:
-		fmt.Printf(applicationPackage+po.opts.InfoPattern+message+backSlashN, vals...)
+		pattern = po.opts.InfoPattern
 	case loglevels.ErrorLevel:
-		fmt.Printf(applicationPackage+po.opts.ErrorPattern+message+backSlashN, vals...)
+		pattern = po.opts.ErrorPattern
+	case loglevels.FatalLevel:
+		pattern = po.opts.FatalPattern
+	}
+	if pattern == "" {
+		return strings.ToUpper(level.String()) + ": "
+	}
+	return pattern
+}
+
+func useColor(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiSequence.ReplaceAllString(s, ""))
+}
+
+func padRight(s string, width int) string {
+	if n := width - visibleLen(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+func padLeft(s string, width int) string {
+	if n := width - visibleLen(s); n > 0 {
+		return strings.Repeat(" ", n) + s
 	}
+	return s
 }
@@ -0,0 +1,45 @@
+package outputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+func TestPackageColor(t *testing.T) {
+	entry := Entry{Level: loglevels.InfoLevel, Package: "db", PackageTag: "(Db)   ", PackageColor: "\033[34m", Message: "connected"}
+	tests := []struct {
+		name string
+		mode ColorMode
+		want string
+	}{
+		{"always", ColorAlways, "\033[34m(Db)" + colorReset + "   " + levelColors[loglevels.InfoLevel] + "INFO: " + colorReset + "connected\n"},
+		{"never", ColorNever, "(Db)   INFO: connected\n"},
+		// A buffer is not a terminal.
+		{"auto", ColorAuto, "(Db)   INFO: connected\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			NewPlainOutput(PlainOutputOpts{Writer: &buf, Color: tt.mode}).LogEntry(entry)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageColorNotInJSON(t *testing.T) {
+	var buf bytes.Buffer
+	NewJsonWriterOutput(&buf).LogEntry(Entry{Level: loglevels.InfoLevel, Package: "db", PackageTag: "(Db) ", PackageColor: "\033[34m", Message: "connected"})
+	if strings.Contains(buf.String(), "\\u001b") || strings.Contains(buf.String(), "\033") {
+		t.Errorf("json output contains ANSI codes: %q", buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+}
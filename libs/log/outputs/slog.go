@@ -0,0 +1,46 @@
+package outputs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+// SlogOutput forwards GUH entries to any slog.Handler.
+type SlogOutput struct {
+	handler slog.Handler
+}
+
+func NewSlogOutput(handler slog.Handler) *SlogOutput {
+	return &SlogOutput{handler: handler}
+}
+
+func (so *SlogOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	so.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
+}
+
+func (so *SlogOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	so.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (so *SlogOutput) LogEntry(entry Entry) {
+	ctx := context.Background()
+	level := entry.Level.SlogLevel()
+	if !so.handler.Enabled(ctx, level) {
+		return
+	}
+	record := slog.NewRecord(entry.Time, level, entry.Message, entry.PC)
+	if entry.Package != "" {
+		record.AddAttrs(slog.String("package", entry.Package))
+	}
+	for _, key := range entry.SortedFieldKeys() {
+		record.AddAttrs(slog.Any(key, entry.Fields[key]))
+	}
+	if entry.TraceID != "" {
+		record.AddAttrs(slog.String("trace_id", entry.TraceID), slog.String("span_id", entry.SpanID))
+	}
+	_ = so.handler.Handle(ctx, record)
+}
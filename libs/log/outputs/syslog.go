@@ -0,0 +1,146 @@
+package outputs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	loglevels "github.com/Arthur-Conti/guh/libs/log/log_levels"
+)
+
+const (
+	syslogFacilityUser = 1
+	// syslogSDID is the structured data id used for entry fields (32473 is the documentation enterprise number).
+	syslogSDID = "fields@32473"
+)
+
+var syslogSeverities = map[loglevels.LogLevel]int{
+	loglevels.TraceLevel:   7,
+	loglevels.DebugLevel:   7,
+	loglevels.InfoLevel:    6,
+	loglevels.WarningLevel: 4,
+	loglevels.ErrorLevel:   3,
+	loglevels.FatalLevel:   2,
+}
+
+type SyslogOutputOpts struct {
+	// Network is "udp", "tcp", "unix" or "unixgram". Stream networks use octet-counting framing (RFC 6587).
+	Network  string
+	Address  string
+	Facility int
+	AppName  string
+	Hostname string
+	// MsgID defaults to the entry's application package.
+	MsgID       string
+	DialTimeout time.Duration
+	// QueueSize is the number of entries waiting to be sent, defaults to 1024. Entries are
+	// dropped when it is full.
+	QueueSize int
+}
+
+// SyslogOutput sends RFC 5424 messages to a syslog server.
+type SyslogOutput struct {
+	opts SyslogOutputOpts
+	conn *netConn
+}
+
+func NewSyslogOutput(opts SyslogOutputOpts) *SyslogOutput {
+	if opts.Network == "" {
+		opts.Network = "udp"
+	}
+	if opts.Facility == 0 {
+		opts.Facility = syslogFacilityUser
+	}
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	return &SyslogOutput{
+		opts: opts,
+		conn: newNetConn("syslog", opts.Network, opts.Address, opts.DialTimeout, opts.QueueSize),
+	}
+}
+
+func (so *SyslogOutput) Log(applicationPackage string, level loglevels.LogLevel, message string) {
+	so.LogEntry(Entry{Time: time.Now(), Level: level, PackageTag: applicationPackage, Message: message})
+}
+
+func (so *SyslogOutput) Logf(applicationPackage string, level loglevels.LogLevel, message string, vals ...any) {
+	so.Log(applicationPackage, level, fmt.Sprintf(message, vals...))
+}
+
+func (so *SyslogOutput) LogEntry(entry Entry) {
+	msg := so.Format(entry)
+	if isStreamNetwork(so.opts.Network) {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+	if err := so.conn.write([]byte(msg)); err != nil {
+		reportError("syslog", err)
+	}
+}
+
+func (so *SyslogOutput) Close() error {
+	return so.conn.close()
+}
+
+// Format renders entry as an RFC 5424 message without transport framing.
+func (so *SyslogOutput) Format(entry Entry) string {
+	severity, ok := syslogSeverities[entry.Level]
+	if !ok {
+		severity = 6
+	}
+	msgID := so.opts.MsgID
+	if msgID == "" {
+		msgID = entry.Package
+	}
+	message := entry.Message
+	if entry.Package == "" {
+		message = entry.PackageTag + message
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		so.opts.Facility*8+severity,
+		entry.Time.UTC().Format(time.RFC3339Nano),
+		syslogHeader(so.opts.Hostname, 255),
+		syslogHeader(so.opts.AppName, 48),
+		os.Getpid(),
+		syslogHeader(msgID, 32),
+		syslogStructuredData(entry),
+		message,
+	)
+}
+
+func syslogHeader(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+func syslogStructuredData(entry Entry) string {
+	if len(entry.Fields) == 0 {
+		return "-"
+	}
+	var sb strings.Builder
+	sb.WriteString("[" + syslogSDID)
+	for _, key := range entry.SortedFieldKeys() {
+		name := syslogHeader(strings.NewReplacer("=", "_", "]", "_", `"`, "_").Replace(key), 32)
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "]", `\]`).Replace(fmt.Sprint(entry.Fields[key]))
+		sb.WriteString(" " + name + `="` + value + `"`)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 
+	"github.com/Arthur-Conti/guh/libs/log/logger"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,6 +19,7 @@ type ProjectConfig struct {
 	DbIP         string `yaml:"dbIP"`
 	DbPort       string `yaml:"dbPort"`
 	DbDatabase   string `yaml:"dbDatabase"`
+	Logger       logger.Config `yaml:"logger,omitempty"`
 }
 
 func Load() (*ProjectConfig, error) {
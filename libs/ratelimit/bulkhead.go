@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+type BulkheadOpts struct {
+	Name string
+	// MaxConcurrent defaults to 10.
+	MaxConcurrent int
+	// MaxWait is how long Acquire waits for a free slot, 0 rejects immediately.
+	MaxWait time.Duration
+}
+
+// Bulkhead limits how many calls run at the same time.
+type Bulkhead struct {
+	opts  BulkheadOpts
+	slots chan struct{}
+}
+
+func NewBulkhead(opts BulkheadOpts) *Bulkhead {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 10
+	}
+	return &Bulkhead{opts: opts, slots: make(chan struct{}, opts.MaxConcurrent)}
+}
+
+// Acquire takes a slot, returning a KindResourceExhausted error when none frees up within
+// MaxWait. release must be called once the call is over.
+func (b *Bulkhead) Acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	default:
+	}
+	if b.opts.MaxWait > 0 {
+		timer := time.NewTimer(b.opts.MaxWait)
+		defer timer.Stop()
+		select {
+		case b.slots <- struct{}{}:
+			return b.release, nil
+		case <-ctx.Done():
+			return nil, errorhandler.Wrap(errorhandler.KindResourceExhausted, "bulkhead wait canceled", ctx.Err(), errorhandler.WithOp("ratelimit.Bulkhead.Acquire"), errorhandler.WithFields(map[string]any{"bulkhead": b.opts.Name}))
+		case <-timer.C:
+		}
+	}
+	return nil, errorhandler.New(errorhandler.KindResourceExhausted, "bulkhead is full", errorhandler.WithOp("ratelimit.Bulkhead.Acquire"), errorhandler.WithFields(map[string]any{"bulkhead": b.opts.Name, "max_concurrent": b.opts.MaxConcurrent}))
+}
+
+// Wait blocks until a slot frees up or ctx is done, ignoring MaxWait. It suits queued work
+// that must run eventually rather than calls that should fail fast.
+func (b *Bulkhead) Wait(ctx context.Context) (release func(), err error) {
+	if ctx.Err() == nil {
+		select {
+		case b.slots <- struct{}{}:
+			return b.release, nil
+		case <-ctx.Done():
+		}
+	}
+	return nil, errorhandler.Wrap(errorhandler.KindResourceExhausted, "bulkhead wait canceled", ctx.Err(), errorhandler.WithOp("ratelimit.Bulkhead.Wait"), errorhandler.WithFields(map[string]any{"bulkhead": b.opts.Name}))
+}
+
+func (b *Bulkhead) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+// InFlight returns how many slots are taken.
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+func (b *Bulkhead) release() {
+	<-b.slots
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+func TestBulkheadAcquire(t *testing.T) {
+	b := NewBulkhead(BulkheadOpts{Name: "db", MaxConcurrent: 2})
+	first, err := b.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.InFlight(); got != 2 {
+		t.Errorf("InFlight() = %d, want 2", got)
+	}
+	_, err = b.Acquire(context.Background())
+	if !IsExceeded(err) || !errorhandler.IsKind(err, errorhandler.KindResourceExhausted) {
+		t.Fatalf("Acquire() on a full bulkhead = %v, want exceeded", err)
+	}
+	first()
+	if _, err := b.Acquire(context.Background()); err != nil {
+		t.Errorf("Acquire() after a release = %v", err)
+	}
+}
+
+func TestBulkheadMaxWait(t *testing.T) {
+	b := NewBulkhead(BulkheadOpts{MaxConcurrent: 1, MaxWait: time.Second})
+	release, _ := b.Acquire(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	if _, err := b.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() within MaxWait = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := b.Acquire(ctx); !IsExceeded(err) || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Acquire() with an ending context = %v after %v, want exceeded early", err, time.Since(start))
+	}
+
+	short := NewBulkhead(BulkheadOpts{MaxConcurrent: 1, MaxWait: 10 * time.Millisecond})
+	short.Acquire(context.Background())
+	if _, err := short.Acquire(context.Background()); !IsExceeded(err) {
+		t.Errorf("Acquire() past MaxWait = %v, want exceeded", err)
+	}
+}
+
+func TestBulkheadWait(t *testing.T) {
+	b := NewBulkhead(BulkheadOpts{MaxConcurrent: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Wait(ctx); !IsExceeded(err) {
+		t.Errorf("Wait() with a canceled context = %v, want exceeded even with a free slot", err)
+	}
+
+	release, _ := b.Wait(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release()
+	}()
+	if _, err := b.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() = %v, want a slot once released", err)
+	}
+}
+
+func TestBulkheadDo(t *testing.T) {
+	b := NewBulkhead(BulkheadOpts{MaxConcurrent: 1})
+	err := b.Do(context.Background(), func(context.Context) error {
+		if got := b.InFlight(); got != 1 {
+			t.Errorf("InFlight() during Do = %d, want 1", got)
+		}
+		return errorhandler.New(errorhandler.KindInternal, "boom")
+	})
+	if !errorhandler.IsKind(err, errorhandler.KindInternal) {
+		t.Errorf("Do() = %v, want the call's error", err)
+	}
+	if got := b.InFlight(); got != 0 {
+		t.Errorf("InFlight() after Do = %d, want 0", got)
+	}
+}
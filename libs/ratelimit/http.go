@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+// KeyByIP keys requests by the client address of the connection.
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware answers requests over the limit with a 429 problem response and a Retry-After header.
+func Middleware(limiter *Keyed, key func(*http.Request) string) func(http.Handler) http.Handler {
+	if key == nil {
+		key = KeyByIP
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := limiter.Check(key(r)); err != nil {
+				errorhandler.WriteProblem(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Transport is an http.RoundTripper that waits for the limiter before each request,
+// so outgoing calls stay within a third-party quota.
+type Transport struct {
+	Limiter Limiter
+	// Next defaults to http.DefaultTransport.
+	Next http.RoundTripper
+}
+
+func NewTransport(limiter Limiter, next http.RoundTripper) *Transport {
+	return &Transport{Limiter: limiter, Next: next}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req)
+}
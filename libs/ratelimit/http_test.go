@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter func() Limiter
+		// retryAfter is the expected header, empty when there must be none.
+		retryAfter string
+	}{
+		{"token bucket", func() Limiter { return NewTokenBucket(TokenBucketOpts{Rate: 1, Per: time.Minute}) }, "60"},
+		{"sliding window", func() Limiter { return NewSlidingWindow(SlidingWindowOpts{Limit: 1, Window: time.Hour}) }, "3600"},
+		{"no refill", func() Limiter { return NewTokenBucket(TokenBucketOpts{Burst: 1}) }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyed := NewKeyed(KeyedOpts{New: tt.limiter})
+			handler := Middleware(keyed, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			serve := func(addr string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+				req.RemoteAddr = addr
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec
+			}
+
+			if rec := serve("10.0.0.1:1234"); rec.Code != http.StatusNoContent {
+				t.Fatalf("first request = %d, want 204", rec.Code)
+			}
+			rec := serve("10.0.0.1:5678")
+			if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Content-Type") != errorhandler.ProblemContentType {
+				t.Fatalf("second request = %d %q, want a 429 problem", rec.Code, rec.Header().Get("Content-Type"))
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+			if rec := serve("10.0.0.2:1234"); rec.Code != http.StatusNoContent {
+				t.Errorf("another client = %d, want 204", rec.Code)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(NewTokenBucket(TokenBucketOpts{Burst: 1}), nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, err := client.Get(server.URL); !IsExceeded(err) {
+		t.Errorf("request over the quota = %v, want exceeded", err)
+	}
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type KeyedOpts struct {
+	// New creates the limiter of a key seen for the first time.
+	New func() Limiter
+	// IdleTTL drops limiters unused for that long, defaults to 10 minutes.
+	IdleTTL time.Duration
+}
+
+type keyedEntry struct {
+	limiter  Limiter
+	lastUsed time.Time
+}
+
+// Keyed keeps one limiter per key, e.g. per client IP or API token.
+type Keyed struct {
+	opts      KeyedOpts
+	mu        sync.Mutex
+	limiters  map[string]*keyedEntry
+	lastSweep time.Time
+	// now is time.Now, replaced in tests.
+	now func() time.Time
+}
+
+func NewKeyed(opts KeyedOpts) *Keyed {
+	if opts.IdleTTL <= 0 {
+		opts.IdleTTL = 10 * time.Minute
+	}
+	return &Keyed{opts: opts, limiters: map[string]*keyedEntry{}, lastSweep: time.Now(), now: time.Now}
+}
+
+func (k *Keyed) Get(key string) Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	if now.Sub(k.lastSweep) >= k.opts.IdleTTL {
+		for name, entry := range k.limiters {
+			if now.Sub(entry.lastUsed) >= k.opts.IdleTTL {
+				delete(k.limiters, name)
+			}
+		}
+		k.lastSweep = now
+	}
+	entry, ok := k.limiters[key]
+	if !ok {
+		entry = &keyedEntry{limiter: k.opts.New()}
+		k.limiters[key] = entry
+	}
+	entry.lastUsed = now
+	return entry.limiter
+}
+
+func (k *Keyed) Allow(key string) bool {
+	return k.Get(key).Allow()
+}
+
+func (k *Keyed) Wait(ctx context.Context, key string) error {
+	return k.Get(key).Wait(ctx)
+}
+
+// Check takes a permit for key or returns the Exceeded error.
+func (k *Keyed) Check(key string) error {
+	if ok, retryAfter := k.Get(key).Reserve(); !ok {
+		return Exceeded("ratelimit.Keyed.Check", key, retryAfter)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestKeyed(t *testing.T) {
+	clock := newFakeClock()
+	k := NewKeyed(KeyedOpts{IdleTTL: time.Minute, New: func() Limiter {
+		tb, _ := newTestTokenBucket(TokenBucketOpts{Rate: 1, Per: time.Hour, Burst: 1})
+		return tb
+	}})
+	k.now, k.lastSweep = clock.Now, clock.Now()
+
+	if k.Get("a") != k.Get("a") {
+		t.Fatal("Get() returned different limiters for the same key")
+	}
+	if !k.Allow("a") || k.Allow("a") || !k.Allow("b") {
+		t.Fatal("keys do not have their own limiter")
+	}
+	if err := k.Check("a"); !IsExceeded(err) {
+		t.Errorf("Check() = %v, want exceeded", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	k.Get("b")
+	clock.Advance(31 * time.Second)
+	k.Get("c")
+
+	var keys []string
+	for key := range k.limiters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "b" || keys[1] != "c" {
+		t.Errorf("limiters after the sweep = %q, want [b c]", keys)
+	}
+	if !k.Allow("a") {
+		t.Error("an evicted key did not get a fresh limiter")
+	}
+}
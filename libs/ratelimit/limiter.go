@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+// Limiter is implemented by TokenBucket and SlidingWindow.
+type Limiter interface {
+	// Reserve takes a permit when one is available, otherwise it reports how long until one is.
+	Reserve() (ok bool, retryAfter time.Duration)
+	Allow() bool
+	Wait(ctx context.Context) error
+}
+
+type TokenBucketOpts struct {
+	// Rate is the number of permits added per Per (default one second). A Rate <= 0 never
+	// refills: Burst permits are granted in total, then calls are rejected without a retry hint.
+	Rate float64
+	Per  time.Duration
+	// Burst is the bucket size, defaults to max(1, Rate).
+	Burst int
+}
+
+type TokenBucket struct {
+	rate   float64
+	burst  float64
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	// now is time.Now, replaced in tests.
+	now func() time.Time
+}
+
+func NewTokenBucket(opts TokenBucketOpts) *TokenBucket {
+	if opts.Per <= 0 {
+		opts.Per = time.Second
+	}
+	if opts.Rate < 0 {
+		opts.Rate = 0
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = int(math.Max(1, opts.Rate))
+	}
+	return &TokenBucket{
+		rate:   opts.Rate / opts.Per.Seconds(),
+		burst:  float64(opts.Burst),
+		tokens: float64(opts.Burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+func (tb *TokenBucket) Reserve() (bool, time.Duration) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	now := tb.now()
+	tb.tokens = math.Min(tb.burst, tb.tokens+now.Sub(tb.last).Seconds()*tb.rate)
+	tb.last = now
+	if tb.tokens >= 1 {
+		tb.tokens--
+		return true, 0
+	}
+	if tb.rate == 0 {
+		// No permit will ever be available, so there is nothing to wait for.
+		return false, 0
+	}
+	return false, time.Duration((1 - tb.tokens) / tb.rate * float64(time.Second))
+}
+
+func (tb *TokenBucket) Allow() bool {
+	ok, _ := tb.Reserve()
+	return ok
+}
+
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	return wait(ctx, tb)
+}
+
+type SlidingWindowOpts struct {
+	// Limit permits are allowed in any Window (default one second), defaults to 1.
+	Limit  int
+	Window time.Duration
+}
+
+// SlidingWindow approximates a sliding log by weighting the previous fixed window's count
+// by how much of it still overlaps the sliding window.
+type SlidingWindow struct {
+	opts     SlidingWindowOpts
+	mu       sync.Mutex
+	start    time.Time
+	current  int
+	previous int
+	// now is time.Now, replaced in tests.
+	now func() time.Time
+}
+
+func NewSlidingWindow(opts SlidingWindowOpts) *SlidingWindow {
+	if opts.Window <= 0 {
+		opts.Window = time.Second
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+	return &SlidingWindow{opts: opts, start: time.Now(), now: time.Now}
+}
+
+func (sw *SlidingWindow) Reserve() (bool, time.Duration) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	now := sw.now()
+	if elapsed := now.Sub(sw.start); elapsed >= sw.opts.Window {
+		windows := elapsed / sw.opts.Window
+		sw.previous = sw.current
+		if windows > 1 {
+			sw.previous = 0
+		}
+		sw.current = 0
+		sw.start = sw.start.Add(windows * sw.opts.Window)
+	}
+	overlap := 1 - float64(now.Sub(sw.start))/float64(sw.opts.Window)
+	if float64(sw.previous)*overlap+float64(sw.current) < float64(sw.opts.Limit) {
+		sw.current++
+		return true, 0
+	}
+	// Wait until enough of the previous window has slid out, or for the next window.
+	retryAfter := sw.start.Add(sw.opts.Window).Sub(now)
+	if sw.previous > 0 && sw.current < sw.opts.Limit {
+		target := float64(sw.opts.Limit-sw.current) / float64(sw.previous)
+		if at := time.Duration((overlap-target)*float64(sw.opts.Window)) + time.Millisecond; at < retryAfter {
+			retryAfter = at
+		}
+	}
+	return false, max(retryAfter, time.Millisecond)
+}
+
+func (sw *SlidingWindow) Allow() bool {
+	ok, _ := sw.Reserve()
+	return ok
+}
+
+func (sw *SlidingWindow) Wait(ctx context.Context) error {
+	return wait(ctx, sw)
+}
+
+func wait(ctx context.Context, l Limiter) error {
+	for {
+		ok, retryAfter := l.Reserve()
+		if ok {
+			return nil
+		}
+		if retryAfter <= 0 {
+			return Exceeded("ratelimit.Wait", "", 0)
+		}
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) < retryAfter {
+			return Exceeded("ratelimit.Wait", "", retryAfter)
+		}
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errorhandler.Wrap(errorhandler.KindResourceExhausted, "rate limit wait canceled", ctx.Err(), errorhandler.WithOp("ratelimit.Wait"))
+		case <-timer.C:
+		}
+	}
+}
+
+// Exceeded is the KindResourceExhausted error returned when a limit is hit, its RetryAfter
+// hint becomes the Retry-After header of problem responses.
+func Exceeded(op, key string, retryAfter time.Duration) error {
+	opts := []errorhandler.Option{errorhandler.WithOp(op), errorhandler.WithRetryAfter(retryAfter)}
+	if key != "" {
+		opts = append(opts, errorhandler.WithFields(map[string]any{"key": key}))
+	}
+	return errorhandler.New(errorhandler.KindResourceExhausted, "rate limit exceeded", opts...)
+}
+
+// Check takes a permit from l or returns the Exceeded error.
+func Check(l Limiter) error {
+	if ok, retryAfter := l.Reserve(); !ok {
+		return Exceeded("ratelimit.Check", "", retryAfter)
+	}
+	return nil
+}
+
+// IsExceeded reports whether err comes from a rate limiter or a full bulkhead.
+func IsExceeded(err error) bool {
+	var e *errorhandler.Error
+	return errors.As(err, &e) && e.Kind == errorhandler.KindResourceExhausted
+}
@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1_700_000_000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestTokenBucket(opts TokenBucketOpts) (*TokenBucket, *fakeClock) {
+	clock := newFakeClock()
+	tb := NewTokenBucket(opts)
+	tb.now, tb.last = clock.Now, clock.Now()
+	return tb, clock
+}
+
+func newTestSlidingWindow(opts SlidingWindowOpts) (*SlidingWindow, *fakeClock) {
+	clock := newFakeClock()
+	sw := NewSlidingWindow(opts)
+	sw.now, sw.start = clock.Now, clock.Now()
+	return sw, clock
+}
+
+// allowed takes permits until l rejects one, returning how many it got and the retry hint.
+func allowed(l Limiter) (int, time.Duration) {
+	for n := 0; n < 1000; n++ {
+		if ok, retryAfter := l.Reserve(); !ok {
+			return n, retryAfter
+		}
+	}
+	return 1000, 0
+}
+
+func near(got, want time.Duration) bool {
+	diff := got - want
+	return diff > -time.Microsecond && diff < time.Microsecond
+}
+
+func TestTokenBucket(t *testing.T) {
+	tb, clock := newTestTokenBucket(TokenBucketOpts{Rate: 10, Burst: 5})
+
+	if n, retryAfter := allowed(tb); n != 5 || !near(retryAfter, 100*time.Millisecond) {
+		t.Errorf("burst = %d permits, retry after %v, want 5 and 100ms", n, retryAfter)
+	}
+	clock.Advance(250 * time.Millisecond)
+	if n, retryAfter := allowed(tb); n != 2 || !near(retryAfter, 50*time.Millisecond) {
+		t.Errorf("after 250ms = %d permits, retry after %v, want 2 and 50ms", n, retryAfter)
+	}
+	clock.Advance(time.Minute)
+	if n, _ := allowed(tb); n != 5 {
+		t.Errorf("after a long pause = %d permits, want the burst of 5", n)
+	}
+}
+
+func TestTokenBucketDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TokenBucketOpts
+		want int
+	}{
+		{"burst defaults to rate", TokenBucketOpts{Rate: 3}, 3},
+		{"burst at least one", TokenBucketOpts{Rate: 0.5}, 1},
+		{"per minute", TokenBucketOpts{Rate: 60, Per: time.Minute, Burst: 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb, _ := newTestTokenBucket(tt.opts)
+			if n, _ := allowed(tb); n != tt.want {
+				t.Errorf("got %d permits, want %d", n, tt.want)
+			}
+		})
+	}
+	tb, clock := newTestTokenBucket(TokenBucketOpts{Rate: 60, Per: time.Minute, Burst: 1})
+	allowed(tb)
+	clock.Advance(time.Second)
+	if !tb.Allow() {
+		t.Error("60 per minute did not refill a permit after a second")
+	}
+}
+
+func TestTokenBucketWithoutRate(t *testing.T) {
+	for _, rate := range []float64{0, -5} {
+		tb, clock := newTestTokenBucket(TokenBucketOpts{Rate: rate, Burst: 2})
+		clock.Advance(time.Hour)
+		if n, retryAfter := allowed(tb); n != 2 || retryAfter != 0 {
+			t.Errorf("rate %v = %d permits, retry after %v, want 2 and no hint", rate, n, retryAfter)
+		}
+		err := tb.Wait(context.Background())
+		if !IsExceeded(err) {
+			t.Fatalf("Wait() = %v, want an immediate exceeded error", err)
+		}
+		if _, ok := errorhandler.RetryAfter(err); ok {
+			t.Errorf("Wait() error carries a retry hint: %v", err)
+		}
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	sw, clock := newTestSlidingWindow(SlidingWindowOpts{Limit: 10, Window: time.Second})
+
+	if n, retryAfter := allowed(sw); n != 10 || retryAfter != time.Second {
+		t.Errorf("first window = %d permits, retry after %v, want 10 and 1s", n, retryAfter)
+	}
+	// A quarter into the next window, 75% of the previous 10 still count.
+	clock.Advance(1250 * time.Millisecond)
+	if n, retryAfter := allowed(sw); n != 3 || retryAfter != 51*time.Millisecond {
+		t.Errorf("second window = %d permits, retry after %v, want 3 and 51ms", n, retryAfter)
+	}
+	clock.Advance(51 * time.Millisecond)
+	if !sw.Allow() {
+		t.Error("no permit once the hinted delay passed")
+	}
+	// Skipping a whole window forgets the previous count.
+	clock.Advance(2 * time.Second)
+	if n, _ := allowed(sw); n != 10 {
+		t.Errorf("after an idle window = %d permits, want 10", n)
+	}
+}
+
+func TestSlidingWindowRetryAfterWhenCurrentIsFull(t *testing.T) {
+	sw, clock := newTestSlidingWindow(SlidingWindowOpts{Limit: 4, Window: time.Second})
+	allowed(sw)
+	clock.Advance(1900 * time.Millisecond)
+	// The previous window nearly slid out, so the current one fills up to the limit and
+	// only the next window frees a permit.
+	if n, retryAfter := allowed(sw); n != 4 || retryAfter != 100*time.Millisecond {
+		t.Errorf("got %d permits, retry after %v, want 4 and 100ms until the next window", n, retryAfter)
+	}
+}
+
+func TestSlidingWindowDefaultLimit(t *testing.T) {
+	sw, _ := newTestSlidingWindow(SlidingWindowOpts{})
+	if n, _ := allowed(sw); n != 1 {
+		t.Errorf("zero value options = %d permits, want 1", n)
+	}
+}
+
+func TestWait(t *testing.T) {
+	tb := NewTokenBucket(TokenBucketOpts{Rate: 100, Burst: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		if err := tb.Wait(ctx); err != nil {
+			t.Fatalf("Wait() = %v", err)
+		}
+	}
+
+	slow := NewTokenBucket(TokenBucketOpts{Rate: 1, Per: time.Hour, Burst: 1})
+	slow.Allow()
+	start := time.Now()
+	err := slow.Wait(ctx)
+	if !IsExceeded(err) || time.Since(start) > 100*time.Millisecond {
+		t.Errorf("Wait() past the deadline = %v after %v, want an immediate exceeded error", err, time.Since(start))
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := slow.Wait(canceled); !IsExceeded(err) {
+		t.Errorf("Wait() with a canceled context = %v, want an exceeded error", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tb, _ := newTestTokenBucket(TokenBucketOpts{Rate: 2, Burst: 1})
+	if err := Check(tb); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	err := Check(tb)
+	if retryAfter, ok := errorhandler.RetryAfter(err); !IsExceeded(err) || !ok || !near(retryAfter, 500*time.Millisecond) {
+		t.Errorf("Check() = %v, want exceeded with a 500ms hint", err)
+	}
+	if errorhandler.Status(err) != 429 {
+		t.Errorf("Status() = %d, want 429", errorhandler.Status(err))
+	}
+}
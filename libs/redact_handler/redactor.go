@@ -0,0 +1,272 @@
+package redacthandler
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const DefaultMask = "[REDACTED]"
+
+var defaultKeyPatterns = []string{
+	"pass",
+	"pwd",
+	"secret",
+	"token",
+	"authorization",
+	"apikey",
+	"api_key",
+	"cookie",
+	"credential",
+	"private_key",
+}
+
+var defaultValuePatterns = []*regexp.Regexp{
+	// user:password@ in connection strings and URLs
+	regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+(@)`),
+	regexp.MustCompile(`(?i)(bearer\s+)[a-z0-9._~+/=-]+`),
+	regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|token)\s*[=:]\s*)[^\s&;,]+`),
+}
+
+type RedactorOpts struct {
+	// KeyPatterns are matched case-insensitively as substrings of map keys and struct field names.
+	KeyPatterns []string
+	// ValuePatterns replace matches inside strings. When a pattern has groups, the first and
+	// last groups are kept around the mask (e.g. `(://user:)secret(@)`).
+	ValuePatterns []*regexp.Regexp
+	Mask          string
+}
+
+// Redactor masks secrets in log fields and error details. Struct fields tagged
+// `log:"redact"` are always masked and `log:"-"` are dropped.
+type Redactor struct {
+	keys   []string
+	values []*regexp.Regexp
+	mask   string
+}
+
+func NewRedactor(opts RedactorOpts) *Redactor {
+	if opts.Mask == "" {
+		opts.Mask = DefaultMask
+	}
+	keys := make([]string, 0, len(opts.KeyPatterns))
+	for _, key := range opts.KeyPatterns {
+		keys = append(keys, strings.ToLower(key))
+	}
+	return &Redactor{keys: keys, values: opts.ValuePatterns, mask: opts.Mask}
+}
+
+var defaultRedactor = NewRedactor(RedactorOpts{KeyPatterns: defaultKeyPatterns, ValuePatterns: defaultValuePatterns})
+
+func Default() *Redactor {
+	return defaultRedactor
+}
+
+// WithDefaults returns a redactor using the default patterns plus the ones in opts.
+func WithDefaults(opts RedactorOpts) *Redactor {
+	opts.KeyPatterns = append(append([]string{}, defaultKeyPatterns...), opts.KeyPatterns...)
+	opts.ValuePatterns = append(append([]*regexp.Regexp{}, defaultValuePatterns...), opts.ValuePatterns...)
+	return NewRedactor(opts)
+}
+
+func (r *Redactor) SensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.keys {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) String(s string) string {
+	for _, pattern := range r.values {
+		s = r.replace(pattern, s)
+	}
+	return s
+}
+
+// replace masks every match of pattern in s. Groups are read from the match indices on s
+// itself, as matching the matched text again loses the context around it.
+func (r *Redactor) replace(pattern *regexp.Regexp, s string) string {
+	matches := pattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	groups := pattern.NumSubexp()
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		sb.WriteString(s[last:match[0]])
+		if groups > 0 {
+			sb.WriteString(group(s, match, 1))
+		}
+		sb.WriteString(r.mask)
+		if groups > 1 {
+			sb.WriteString(group(s, match, groups))
+		}
+		last = match[1]
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// group returns the text of group i of match, empty when the group did not participate.
+func group(s string, match []int, i int) string {
+	start, end := match[2*i], match[2*i+1]
+	if start < 0 {
+		return ""
+	}
+	return s[start:end]
+}
+
+// Fields returns a redacted copy of fields; the input map is never modified.
+func (r *Redactor) Fields(fields map[string]any) map[string]any {
+	if fields == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(fields))
+	for key, value := range fields {
+		if r.SensitiveKey(key) {
+			redacted[key] = r.mask
+			continue
+		}
+		redacted[key] = r.Value(value)
+	}
+	return redacted
+}
+
+// Value returns v unchanged when it holds nothing to redact, otherwise a redacted copy
+// where structs become map[string]any keyed by field name.
+func (r *Redactor) Value(v any) any {
+	redacted, _ := r.walk(reflect.ValueOf(v), 0)
+	return redacted
+}
+
+const maxDepth = 16
+
+func (r *Redactor) walk(v reflect.Value, depth int) (any, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	original := valueOf(v)
+	if depth > maxDepth {
+		return original, false
+	}
+
+	switch value := original.(type) {
+	case string:
+		redacted := r.String(value)
+		return redacted, redacted != value
+	case error:
+		msg := value.Error()
+		if redacted := r.String(msg); redacted != msg {
+			return redacted, true
+		}
+		return original, false
+	case fmt.Stringer:
+		if v.Kind() == reflect.Struct || v.Kind() == reflect.Pointer {
+			msg := value.String()
+			if redacted := r.String(msg); redacted != msg {
+				return redacted, true
+			}
+			return original, false
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return original, false
+		}
+		redacted, changed := r.walk(v.Elem(), depth+1)
+		if !changed {
+			return original, false
+		}
+		return redacted, true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return original, false
+		}
+		redacted := make(map[string]any, v.Len())
+		changed := false
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if r.SensitiveKey(key) {
+				redacted[key] = r.mask
+				changed = true
+				continue
+			}
+			value, valueChanged := r.walk(iter.Value(), depth+1)
+			redacted[key] = value
+			changed = changed || valueChanged
+		}
+		if !changed {
+			return original, false
+		}
+		return redacted, true
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return original, false
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return original, false
+		}
+		redacted := make([]any, v.Len())
+		changed := false
+		for i := 0; i < v.Len(); i++ {
+			value, valueChanged := r.walk(v.Index(i), depth+1)
+			redacted[i] = value
+			changed = changed || valueChanged
+		}
+		if !changed {
+			return original, false
+		}
+		return redacted, true
+	case reflect.Struct:
+		return r.walkStruct(v, original, depth)
+	}
+	return original, false
+}
+
+func (r *Redactor) walkStruct(v reflect.Value, original any, depth int) (any, bool) {
+	t := v.Type()
+	redacted := make(map[string]any, t.NumField())
+	changed := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		switch field.Tag.Get("log") {
+		case "-":
+			changed = true
+			continue
+		case "redact":
+			redacted[field.Name] = r.mask
+			changed = true
+			continue
+		}
+		if r.SensitiveKey(field.Name) {
+			redacted[field.Name] = r.mask
+			changed = true
+			continue
+		}
+		value, valueChanged := r.walk(v.Field(i), depth+1)
+		redacted[field.Name] = value
+		changed = changed || valueChanged
+	}
+	if !changed {
+		return original, false
+	}
+	return redacted, true
+}
+
+func valueOf(v reflect.Value) any {
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
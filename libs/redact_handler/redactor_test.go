@@ -0,0 +1,155 @@
+package redacthandler
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type credentials struct {
+	User     string
+	Password string
+}
+
+type account struct {
+	Name    string
+	Pin     string `log:"redact"`
+	Ignored string `log:"-"`
+	Creds   *credentials
+}
+
+type plain struct {
+	Name string
+	Age  int
+}
+
+func TestFields(t *testing.T) {
+	r := Default()
+	tests := []struct {
+		name   string
+		fields map[string]any
+		want   map[string]any
+	}{
+		{
+			name:   "sensitive top level keys",
+			fields: map[string]any{"password": "hunter2", "Authorization": "Bearer abc", "user": "bob"},
+			want:   map[string]any{"password": DefaultMask, "Authorization": DefaultMask, "user": "bob"},
+		},
+		{
+			name: "nested map[string]any",
+			fields: map[string]any{"db": map[string]any{
+				"host":   "localhost",
+				"secret": "s3cr3t",
+				"inner":  map[string]any{"api_key": "k", "port": 5432},
+			}},
+			want: map[string]any{"db": map[string]any{
+				"host":   "localhost",
+				"secret": DefaultMask,
+				"inner":  map[string]any{"api_key": DefaultMask, "port": 5432},
+			}},
+		},
+		{
+			name:   "nested map[string]string",
+			fields: map[string]any{"headers": map[string]string{"Cookie": "session=1", "Accept": "json"}},
+			want:   map[string]any{"headers": map[string]any{"Cookie": DefaultMask, "Accept": "json"}},
+		},
+		{
+			name:   "struct behind pointer",
+			fields: map[string]any{"creds": &credentials{User: "bob", Password: "hunter2"}},
+			want:   map[string]any{"creds": map[string]any{"User": "bob", "Password": DefaultMask}},
+		},
+		{
+			name:   "log tags",
+			fields: map[string]any{"account": account{Name: "main", Pin: "1234", Ignored: "x", Creds: &credentials{User: "bob"}}},
+			want: map[string]any{"account": map[string]any{
+				"Name":  "main",
+				"Pin":   DefaultMask,
+				"Creds": map[string]any{"User": "bob", "Password": DefaultMask},
+			}},
+		},
+		{
+			name:   "unchanged struct is kept as is",
+			fields: map[string]any{"user": plain{Name: "bob", Age: 3}},
+			want:   map[string]any{"user": plain{Name: "bob", Age: 3}},
+		},
+		{
+			name:   "slices",
+			fields: map[string]any{"dsns": []string{"postgres://bob:hunter2@db:5432/app", "plain"}},
+			want:   map[string]any{"dsns": []any{"postgres://bob:" + DefaultMask + "@db:5432/app", "plain"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Fields(tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	r := Default()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"dsn", "dial postgres://bob:hunter2@db:5432/app failed", "dial postgres://bob:" + DefaultMask + "@db:5432/app failed"},
+		{"bearer", "header Bearer eyJhbGciOi.payload.sig", "header Bearer " + DefaultMask},
+		{"password pair", "login password=hunter2&user=bob", "login password=" + DefaultMask + "&user=bob"},
+		{"token colon", "token: abc123", "token: " + DefaultMask},
+		{"nothing to redact", "user bob logged in", "user bob logged in"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldsDoesNotModifyInput(t *testing.T) {
+	nested := map[string]any{"token": "abc", "id": 1}
+	fields := map[string]any{"password": "hunter2", "nested": nested, "dsn": "postgres://bob:hunter2@db/app"}
+
+	Default().Fields(fields)
+
+	want := map[string]any{
+		"password": "hunter2",
+		"nested":   map[string]any{"token": "abc", "id": 1},
+		"dsn":      "postgres://bob:hunter2@db/app",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("input modified: %#v", fields)
+	}
+}
+
+func TestFieldsNil(t *testing.T) {
+	if got := Default().Fields(nil); got != nil {
+		t.Errorf("Fields(nil) = %#v, want nil", got)
+	}
+}
+
+func TestStringContextPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		in      string
+		want    string
+	}{
+		// Matched alone, "xy" has no \B before x, so re-matching the match would find nothing.
+		{"non word boundary", `\B(x)y`, "axy bxy xy", "ax" + DefaultMask + " bx" + DefaultMask + " xy"},
+		{"lookbehind like anchor", `(?m)^(key=)\S+`, "a\nkey=1\nkey=2", "a\nkey=" + DefaultMask + "\nkey=" + DefaultMask},
+		{"optional group unmatched", `(pin:)?\d{4}(!)?`, "code 1234 pin:5678!", "code " + DefaultMask + " pin:" + DefaultMask + "!"},
+		{"no groups", `\d{3}-\d{4}`, "call 555-1234 now", "call " + DefaultMask + " now"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRedactor(RedactorOpts{ValuePatterns: []*regexp.Regexp{regexp.MustCompile(tt.pattern)}})
+			if got := r.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package retryhandler
+
+import (
+	"sync"
+	"time"
+)
+
+type BudgetOpts struct {
+	// Ratio is the share of calls that may be retried, defaults to 0.1 (one retry per ten calls).
+	Ratio float64
+	// MinPerSecond keeps a trickle of retries available when traffic is low, defaults to 1.
+	MinPerSecond float64
+	// MaxTokens caps how many retries can be saved up, defaults to 10.
+	MaxTokens float64
+}
+
+// Budget is a token bucket shared across callers: every call deposits Ratio tokens and
+// every retry withdraws one, so an outage cannot multiply traffic by MaxAttempts.
+type Budget struct {
+	opts   BudgetOpts
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func NewBudget(opts BudgetOpts) *Budget {
+	if opts.Ratio <= 0 {
+		opts.Ratio = 0.1
+	}
+	if opts.MinPerSecond <= 0 {
+		opts.MinPerSecond = 1
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = 10
+	}
+	return &Budget{opts: opts, tokens: opts.MinPerSecond, last: time.Now(), now: time.Now}
+}
+
+// Tokens returns how many retries are currently available.
+func (b *Budget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	return b.tokens
+}
+
+func (b *Budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.tokens = min(b.tokens+b.opts.Ratio, b.opts.MaxTokens)
+}
+
+func (b *Budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *Budget) refill() {
+	now := b.now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.opts.MinPerSecond, b.opts.MaxTokens)
+	b.last = now
+}
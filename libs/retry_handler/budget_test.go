@@ -0,0 +1,115 @@
+package retryhandler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1_700_000_000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestBudget(opts BudgetOpts, clock *fakeClock) *Budget {
+	b := NewBudget(opts)
+	b.now = clock.Now
+	b.last = clock.Now()
+	return b
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBudget(t *testing.T) {
+	clock := newFakeClock()
+	b := newTestBudget(BudgetOpts{Ratio: 0.5, MinPerSecond: 1, MaxTokens: 3}, clock)
+
+	if got := b.Tokens(); !almostEqual(got, 1) {
+		t.Fatalf("initial tokens = %v, want MinPerSecond", got)
+	}
+	if !b.withdraw() {
+		t.Fatal("first withdraw refused")
+	}
+	if b.withdraw() {
+		t.Fatal("withdraw allowed with an empty budget")
+	}
+
+	b.deposit()
+	b.deposit()
+	if got := b.Tokens(); !almostEqual(got, 1) {
+		t.Errorf("tokens after two deposits = %v, want 2 * Ratio", got)
+	}
+
+	clock.Advance(1500 * time.Millisecond)
+	if got := b.Tokens(); !almostEqual(got, 2.5) {
+		t.Errorf("tokens after 1.5s = %v, want 2.5", got)
+	}
+	clock.Advance(time.Minute)
+	if got := b.Tokens(); !almostEqual(got, 3) {
+		t.Errorf("tokens after a minute = %v, want MaxTokens", got)
+	}
+	b.deposit()
+	if got := b.Tokens(); !almostEqual(got, 3) {
+		t.Errorf("deposit went past MaxTokens: %v", got)
+	}
+}
+
+func TestBudgetDefaults(t *testing.T) {
+	b := NewBudget(BudgetOpts{})
+	if b.opts.Ratio != 0.1 || b.opts.MinPerSecond != 1 || b.opts.MaxTokens != 10 {
+		t.Errorf("defaults = %+v", b.opts)
+	}
+}
+
+func TestDoBudgetExhausted(t *testing.T) {
+	clock := newFakeClock()
+	budget := newTestBudget(BudgetOpts{Ratio: 0.1, MinPerSecond: 1, MaxTokens: 1}, clock)
+	policy := Policy{MaxAttempts: 5, InitialDelay: time.Millisecond, Budget: budget}
+	failure := errorhandler.New(errorhandler.KindUnavailable, "down")
+
+	calls := 0
+	err := Do(context.Background(), policy, func(context.Context) error {
+		calls++
+		return failure
+	})
+	if calls != 2 {
+		t.Errorf("calls = %d, want one retry paid by the budget", calls)
+	}
+	var e *errorhandler.Error
+	if !errors.As(err, &e) || e.Fields["budget_exhausted"] != true || e.Fields["attempts"] != 2 {
+		t.Fatalf("Do() = %#v, want budget_exhausted after 2 attempts", err)
+	}
+
+	calls = 0
+	clock.Advance(time.Second)
+	Do(context.Background(), policy, func(context.Context) error {
+		calls++
+		return failure
+	})
+	if calls != 2 {
+		t.Errorf("calls after refill = %d, want the refilled token to pay one retry", calls)
+	}
+}
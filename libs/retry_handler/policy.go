@@ -0,0 +1,165 @@
+package retryhandler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+type BackoffStrategy int
+
+const (
+	BackoffExponential BackoffStrategy = iota
+	BackoffLinear
+	BackoffConstant
+)
+
+type Jitter int
+
+const (
+	JitterNone Jitter = iota
+	// JitterFull waits a random duration between 0 and the computed delay.
+	JitterFull
+	// JitterEqual keeps half of the computed delay and randomises the other half.
+	JitterEqual
+)
+
+type Policy struct {
+	// MaxAttempts counts the first call, defaults to 3.
+	MaxAttempts int
+	// InitialDelay defaults to 100ms.
+	InitialDelay time.Duration
+	// MaxDelay caps a single wait, 0 means no cap.
+	MaxDelay time.Duration
+	Backoff  BackoffStrategy
+	// Multiplier is the exponential growth factor, defaults to 2.
+	Multiplier float64
+	Jitter     Jitter
+	// MaxElapsed stops retrying when the next wait would go past it, 0 means no limit.
+	MaxElapsed time.Duration
+	// Retryable decides whether an error is retried, defaults to errorhandler.Retryable.
+	Retryable func(error) bool
+	// OnRetry is called before waiting for attempt+1, e.g. to log the failure.
+	OnRetry func(attempt int, err error, delay time.Duration)
+	// Budget, when set, is shared by every caller using the policy to cap retries overall.
+	Budget *Budget
+}
+
+func DefaultPolicy() Policy {
+	return Policy{MaxAttempts: 3, InitialDelay: 100 * time.Millisecond, Multiplier: 2}
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = 100 * time.Millisecond
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = 2
+	}
+	if p.Retryable == nil {
+		p.Retryable = errorhandler.Retryable
+	}
+	return p
+}
+
+// Delay returns the wait after the given failed attempt (starting at 1), jitter included.
+func (p Policy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+	var delay float64
+	switch p.Backoff {
+	case BackoffLinear:
+		delay = float64(p.InitialDelay) * float64(attempt)
+	case BackoffConstant:
+		delay = float64(p.InitialDelay)
+	default:
+		delay = float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which does not fit a Duration.
+	var d time.Duration
+	switch {
+	case delay >= float64(math.MaxInt64):
+		d = math.MaxInt64
+	case delay > 0:
+		d = time.Duration(delay)
+	}
+	if d <= 0 {
+		return 0
+	}
+	switch p.Jitter {
+	case JitterFull:
+		d = time.Duration(rand.Int64N(int64(d)))
+	case JitterEqual:
+		half := d / 2
+		d = half + time.Duration(rand.Int64N(int64(d-half)))
+	}
+	return d
+}
+
+// Do calls fn until it succeeds, returns an error the policy does not retry, runs out of
+// attempts, time or retry budget, or ctx is done. The last error is returned wrapped with the
+// number of attempts and keeps its Kind. A RetryAfter hint on the error replaces shorter delays.
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// DoValue is Do for functions returning a value, the zero value is returned on failure.
+func DoValue[T any](ctx context.Context, policy Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	policy = policy.withDefaults()
+	if policy.Budget != nil {
+		policy.Budget.deposit()
+	}
+	start := time.Now()
+	var zero T
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+		if attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			return zero, gaveUp(err, attempt)
+		}
+		delay := policy.Delay(attempt)
+		if hint, ok := errorhandler.RetryAfter(err); ok && hint > delay {
+			delay = hint
+		}
+		if policy.MaxElapsed > 0 && delay > policy.MaxElapsed-time.Since(start) {
+			return zero, gaveUp(err, attempt)
+		}
+		if policy.Budget != nil && !policy.Budget.withdraw() {
+			return zero, gaveUp(err, attempt, errorhandler.WithFields(map[string]any{"attempts": attempt, "budget_exhausted": true}))
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, gaveUp(errors.Join(err, ctx.Err()), attempt)
+		case <-timer.C:
+		}
+	}
+}
+
+func gaveUp(err error, attempts int, opts ...errorhandler.Option) error {
+	kind := errorhandler.KindInternal
+	var e *errorhandler.Error
+	if errors.As(err, &e) {
+		kind = e.Kind
+	}
+	opts = append([]errorhandler.Option{errorhandler.WithOp("retryhandler.Do"), errorhandler.WithFields(map[string]any{"attempts": attempts})}, opts...)
+	return errorhandler.Wrap(kind, "retry gave up", err, opts...)
+}
@@ -0,0 +1,172 @@
+package retryhandler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		attempt int
+		want    time.Duration
+	}{
+		{"exponential", Policy{InitialDelay: 100 * time.Millisecond}, 3, 400 * time.Millisecond},
+		{"linear", Policy{InitialDelay: 100 * time.Millisecond, Backoff: BackoffLinear}, 3, 300 * time.Millisecond},
+		{"constant", Policy{InitialDelay: 100 * time.Millisecond, Backoff: BackoffConstant}, 3, 100 * time.Millisecond},
+		{"max delay", Policy{InitialDelay: time.Second, MaxDelay: 5 * time.Second}, 10, 5 * time.Second},
+		{"overflowing exponent", Policy{}, 70, math.MaxInt64},
+		{"infinite exponent", Policy{}, 5000, math.MaxInt64},
+		{"negative linear attempt", Policy{Backoff: BackoffLinear}, -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	for _, attempt := range []int{1, 5, 70, 5000} {
+		full := Policy{Jitter: JitterFull}
+		equal := Policy{Jitter: JitterEqual}
+		ceiling := Policy{}.Delay(attempt)
+		for i := 0; i < 100; i++ {
+			if got := full.Delay(attempt); got < 0 || got > ceiling {
+				t.Fatalf("full jitter Delay(%d) = %v, want within [0, %v]", attempt, got, ceiling)
+			}
+			if got := equal.Delay(attempt); got < ceiling/2 || got > ceiling {
+				t.Fatalf("equal jitter Delay(%d) = %v, want within [%v, %v]", attempt, got, ceiling/2, ceiling)
+			}
+		}
+	}
+	if got := (Policy{Backoff: BackoffLinear, Jitter: JitterFull}).Delay(0); got != 0 {
+		t.Errorf("full jitter on a zero delay = %v, want 0", got)
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	unavailable := errorhandler.New(errorhandler.KindUnavailable, "down")
+	tests := []struct {
+		name     string
+		policy   Policy
+		failures []error
+		calls    int
+		kind     errorhandler.Kind
+		attempts int
+	}{
+		{"first call succeeds", Policy{}, nil, 1, 0, 0},
+		{"succeeds after retries", Policy{MaxAttempts: 3}, []error{unavailable, unavailable}, 3, 0, 0},
+		{"runs out of attempts", Policy{MaxAttempts: 3}, []error{unavailable, unavailable, unavailable, unavailable}, 3, errorhandler.KindUnavailable, 3},
+		{"zero MaxAttempts defaults to 3", Policy{}, []error{unavailable, unavailable, unavailable, unavailable}, 3, errorhandler.KindUnavailable, 3},
+		{"not retryable", Policy{MaxAttempts: 3}, []error{errorhandler.New(errorhandler.KindNotFound, "missing")}, 1, errorhandler.KindNotFound, 1},
+		{"plain error becomes internal", Policy{MaxAttempts: 3, Retryable: func(error) bool { return false }}, []error{errors.New("boom")}, 1, errorhandler.KindInternal, 1},
+		{"custom predicate", Policy{MaxAttempts: 2, Retryable: func(error) bool { return true }}, []error{errors.New("boom"), errors.New("boom")}, 2, errorhandler.KindInternal, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.policy.InitialDelay = time.Millisecond
+			calls := 0
+			value, err := DoValue(context.Background(), tt.policy, func(context.Context) (int, error) {
+				calls++
+				if calls <= len(tt.failures) {
+					return 0, tt.failures[calls-1]
+				}
+				return 42, nil
+			})
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+			if tt.attempts == 0 {
+				if err != nil || value != 42 {
+					t.Errorf("DoValue() = %v, %v, want 42", value, err)
+				}
+				return
+			}
+			if value != 0 {
+				t.Errorf("value on failure = %v, want the zero value", value)
+			}
+			var e *errorhandler.Error
+			if !errors.As(err, &e) || e.Kind != tt.kind || e.Op != "retryhandler.Do" || e.Fields["attempts"] != tt.attempts {
+				t.Fatalf("DoValue() error = %#v, want kind %v after %d attempts", err, tt.kind, tt.attempts)
+			}
+			if !errors.Is(err, tt.failures[tt.calls-1]) {
+				t.Errorf("error does not wrap the last failure: %v", err)
+			}
+		})
+	}
+}
+
+func TestDoRetryAfterHint(t *testing.T) {
+	var delays []time.Duration
+	policy := Policy{
+		MaxAttempts:  2,
+		InitialDelay: time.Millisecond,
+		OnRetry:      func(_ int, _ error, delay time.Duration) { delays = append(delays, delay) },
+	}
+	err := Do(context.Background(), policy, func(context.Context) error {
+		return errorhandler.New(errorhandler.KindResourceExhausted, "slow down", errorhandler.WithRetryAfter(20*time.Millisecond))
+	})
+	if err == nil || len(delays) != 1 || delays[0] != 20*time.Millisecond {
+		t.Errorf("delays = %v, want the 20ms hint", delays)
+	}
+}
+
+func TestDoMaxElapsed(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := Do(context.Background(), Policy{MaxAttempts: 10, InitialDelay: 50 * time.Millisecond, Backoff: BackoffConstant, MaxElapsed: 120 * time.Millisecond}, func(context.Context) error {
+		calls++
+		return errorhandler.New(errorhandler.KindUnavailable, "down")
+	})
+	// 50ms waits fit twice into 120ms; a slow machine may only fit one.
+	if calls < 2 || calls > 3 {
+		t.Errorf("calls = %d, want 2 or 3 within MaxElapsed", calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Do() took %v, want it to stop at MaxElapsed", elapsed)
+	}
+	if !errorhandler.IsKind(err, errorhandler.KindUnavailable) {
+		t.Errorf("Do() = %v, want the unavailable error", err)
+	}
+}
+
+func TestDoContextCanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	failure := errorhandler.New(errorhandler.KindUnavailable, "down")
+	policy := Policy{
+		MaxAttempts:  5,
+		InitialDelay: time.Hour,
+		OnRetry:      func(int, error, time.Duration) { cancel() },
+	}
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(ctx, policy, func(context.Context) error {
+			calls++
+			return failure
+		})
+	}()
+	select {
+	case err := <-done:
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, failure) {
+			t.Errorf("Do() = %v, want both the failure and context.Canceled", err)
+		}
+		if !errorhandler.IsKind(err, errorhandler.KindUnavailable) || errorhandler.Retryable(err) {
+			t.Errorf("Do() = %v, want the failure kind and no further retries", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do() kept waiting after ctx was canceled")
+	}
+}
@@ -1,9 +1,8 @@
 package retryhandler
 
 import (
+	"context"
 	"time"
-
-	errorhandler "github.com/Arthur-Conti/guh/libs/error_handler"
 )
 
 type RetryOpts struct {
@@ -11,6 +10,9 @@ type RetryOpts struct {
 	Backoff     int
 }
 
+// RetryHandler is the original fixed-backoff API.
+//
+// Deprecated: use Do with a Policy.
 type RetryHandler struct {
 	opts RetryOpts
 }
@@ -19,24 +21,24 @@ func NewRetryHandler(opts RetryOpts) *RetryHandler {
 	return &RetryHandler{opts: opts}
 }
 
+// Do retries function with a constant Backoff in seconds, using the handler's options when
+// df is true. Every error is retried and the last one is returned. A MaxAttempts of 0 makes
+// a single call, unlike Policy where it means the default of 3.
 func (rh *RetryHandler) Do(function func() error, opts RetryOpts, df bool) error {
-	var maxAttempts int
-	var backoff int
 	if df {
-		maxAttempts = rh.opts.MaxAttempts
-		backoff = rh.opts.Backoff
-	} else {
-		maxAttempts = opts.MaxAttempts
-		backoff = opts.Backoff
+		opts = rh.opts
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	policy := Policy{
+		MaxAttempts:  opts.MaxAttempts,
+		InitialDelay: time.Duration(opts.Backoff) * time.Second,
+		Backoff:      BackoffConstant,
+		Retryable:    func(error) bool { return true },
 	}
-	err := errorhandler.New(errorhandler.KindInternal, "starting error", errorhandler.WithOp("retry_handler.Do")) 
-	var count int
-	for err != nil && count < maxAttempts {
-		err = function()
-		count += 1
-		if backoff != 0 {
-			time.Sleep(time.Duration(backoff)*time.Second)
-		}
+	if opts.Backoff <= 0 {
+		policy.InitialDelay = time.Nanosecond
 	}
-	return nil
+	return Do(context.Background(), policy, func(context.Context) error { return function() })
 }
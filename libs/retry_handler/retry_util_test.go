@@ -0,0 +1,37 @@
+package retryhandler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRetryHandlerDo(t *testing.T) {
+	failure := errors.New("boom")
+	tests := []struct {
+		name  string
+		opts  RetryOpts
+		df    bool
+		calls int
+	}{
+		{"zero attempts makes one call", RetryOpts{}, false, 1},
+		{"negative attempts makes one call", RetryOpts{MaxAttempts: -2}, false, 1},
+		{"explicit attempts", RetryOpts{MaxAttempts: 4}, false, 4},
+		{"handler defaults", RetryOpts{MaxAttempts: 9}, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rh := NewRetryHandler(RetryOpts{MaxAttempts: 2})
+			calls := 0
+			err := rh.Do(func() error {
+				calls++
+				return failure
+			}, tt.opts, tt.df)
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+			if !errors.Is(err, failure) {
+				t.Errorf("Do() = %v, want the last failure", err)
+			}
+		})
+	}
+}
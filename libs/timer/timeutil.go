@@ -1,7 +1,6 @@
 package timer
 
 import (
-	"fmt"
 	"time"
 
 	fl "github.com/Arthur-Conti/guh/libs/fast_logger"
@@ -40,7 +39,7 @@ func (t *Timer) End() time.Duration {
 }
 
 func (t *Timer) EndAndPrint(message string) {
-	fmt.Println(message, time.Since(t.NowTime))
+	fl.Infof("%s %v", message, time.Since(t.NowTime))
 }
 
 func (t *Timer) ClassifyTime(duration time.Duration) int {
@@ -60,5 +59,5 @@ func (t *Timer) TestFunction(function func()) {
 	function()
 	duration := t.End()
 	grade := TimerGradeMap[t.ClassifyTime(duration)]
-	fl.Logf("Function was classified as %v grade. It took: %v\n", grade, duration)
+	fl.Infof("Function was classified as %v grade. It took: %v", grade, duration)
 }
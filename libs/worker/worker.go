@@ -2,10 +2,12 @@ package worker
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	fl "github.com/Arthur-Conti/guh/libs/fast_logger"
 	httphandler "github.com/Arthur-Conti/guh/libs/http_handler"
+	"github.com/Arthur-Conti/guh/libs/ratelimit"
 	"github.com/google/uuid"
 )
 
@@ -30,13 +32,16 @@ func (w *Worker[T]) DoAndNotify(function func() (T, error), url string) {
 	go func() {
 		response, err := function()
 		if err != nil {
-			httphandler.NewHttpHandler().Request("POST", url, map[string]any{
+			fl.Errorf("Worker %v failed: %v", w.ID, err)
+			if notifyErr := httphandler.NewHttpHandler().Request("POST", url, map[string]any{
 				"error": err.Error(),
-			}, nil)
+			}, nil); notifyErr != nil {
+				fl.Errorf("Error sending error to %s: %v", url, notifyErr)
+			}
 		}
 		err = httphandler.NewHttpHandler().Request("POST", url, response, nil)
 		if err != nil {
-			fl.Logf("Error sending response to %s: %v", url, err)
+			fl.Errorf("Error sending response to %s: %v", url, err)
 		}
 	}()
 }
@@ -63,3 +68,35 @@ func (w *Worker[T]) Pool(functions []func()) {
 		go function()
 	}
 }
+
+// DoLimited runs function in a goroutine once limiter grants a permit, dropping it when ctx ends first.
+func (w *Worker[T]) DoLimited(ctx context.Context, limiter ratelimit.Limiter, function func()) {
+	go func() {
+		if err := limiter.Wait(ctx); err != nil {
+			fl.Errorf("Worker %v skipped: %v", w.ID, err)
+			return
+		}
+		function()
+	}()
+}
+
+// PoolWithBulkhead runs functions through bulkhead so at most its MaxConcurrent run at once,
+// waiting for a free slot before starting each one. Functions not started when ctx ends are
+// logged and skipped. It returns once all started functions finished.
+func (w *Worker[T]) PoolWithBulkhead(ctx context.Context, bulkhead *ratelimit.Bulkhead, functions []func()) {
+	var wg sync.WaitGroup
+	for i, function := range functions {
+		release, err := bulkhead.Wait(ctx)
+		if err != nil {
+			fl.Errorf("Worker %v skipped %d functions: %v", w.ID, len(functions)-i, err)
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+			function()
+		}()
+	}
+	wg.Wait()
+}
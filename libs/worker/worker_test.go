@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Arthur-Conti/guh/libs/ratelimit"
+	"github.com/google/uuid"
+)
+
+func TestPoolWithBulkheadRunsEveryFunction(t *testing.T) {
+	w := NewWorker[any](uuid.New())
+	bulkhead := ratelimit.NewBulkhead(ratelimit.BulkheadOpts{MaxConcurrent: 2})
+
+	var ran, running, peak atomic.Int32
+	functions := make([]func(), 10)
+	for i := range functions {
+		functions[i] = func() {
+			current := running.Add(1)
+			for {
+				previous := peak.Load()
+				if current <= previous || peak.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			ran.Add(1)
+		}
+	}
+	w.PoolWithBulkhead(context.Background(), bulkhead, functions)
+
+	if got := ran.Load(); got != 10 {
+		t.Errorf("ran %d functions, want 10", got)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("%d functions ran at once, want at most 2", got)
+	}
+	if got := bulkhead.InFlight(); got != 0 {
+		t.Errorf("InFlight() = %d after the pool, want 0", got)
+	}
+}
+
+func TestPoolWithBulkheadStopsWhenContextEnds(t *testing.T) {
+	w := NewWorker[any](uuid.New())
+	bulkhead := ratelimit.NewBulkhead(ratelimit.BulkheadOpts{MaxConcurrent: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ran atomic.Int32
+	functions := make([]func(), 5)
+	for i := range functions {
+		functions[i] = func() {
+			ran.Add(1)
+			cancel()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	w.PoolWithBulkhead(ctx, bulkhead, functions)
+
+	if got := ran.Load(); got != 1 {
+		t.Errorf("ran %d functions, want 1", got)
+	}
+}